@@ -4,12 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"log"
+	"os"
 	"os/signal"
 	"sync"
 	"syscall"
 	"time"
 
 	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 
 	"github.com/fevse/songlib/internal/app"
 	"github.com/fevse/songlib/internal/config"
@@ -20,25 +22,46 @@ import (
 func main() {
 	conf := config.LoadConfig()
 
-	db, err := sql.Open("postgres", conf.DBConnectionString())
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate(conf, os.Args[2:]); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+		return
 	}
-	defer db.Close()
 
-	if err := db.Ping(); err != nil {
-		log.Fatalf("Failed to ping database: %v", err)
-	}
+	var repo storage.Repository
+	switch conf.DBDriver {
+	case "memory":
+		log.Println("Using in-memory storage, data will be lost on restart")
+		storage := storage.NewMemoryStorage()
+		if err := storage.SetUniqueSongs(context.Background(), conf.UniqueSongs); err != nil {
+			log.Fatalf("Failed to set unique songs: %v", err)
+		}
+		repo = storage
+	case "postgres", "sqlite":
+		db, err := openDB(conf)
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer db.Close()
 
-	storage := storage.NewStorage(db)
+		storage := storage.NewStorage(db, conf.DBDriver)
 
-	err = storage.Migrate()
-	if err != nil {
-		log.Fatalf("Failed to migrate database: %v", err)
+		if conf.MigrateOnStart {
+			if err := storage.Migrate(context.Background()); err != nil {
+				log.Fatalf("Failed to migrate database: %v", err)
+			}
+		}
+		if err := storage.SetUniqueSongs(context.Background(), conf.UniqueSongs); err != nil {
+			log.Fatalf("Failed to set unique songs: %v", err)
+		}
+		repo = storage
+	default:
+		log.Fatalf("Unknown DB_DRIVER: %q", conf.DBDriver)
 	}
 
-	app := app.NewSongLibApp(storage, conf.MIURL)
-	server := server.NewServer(app, conf.ServHost, conf.ServPort)
+	app := app.NewSongLibApp(repo, conf.MIURL)
+	server := server.NewServer(app, conf.ServHost, conf.ServPort, conf.RequestTimeout, conf.IdempotencyTTL)
 
 	ctx, cancel := signal.NotifyContext(context.Background(),
 		syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
@@ -70,3 +93,22 @@ func main() {
 	wg.Wait()
 
 }
+
+func openDB(conf *config.Config) (*sql.DB, error) {
+	db, err := sql.Open(conf.DBDriver, conf.DBConnectionString())
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.DBDriver == "sqlite" {
+		// SQLite allows a single writer, share one connection
+		// instead of failing with SQLITE_BUSY.
+		db.SetMaxOpenConns(1)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
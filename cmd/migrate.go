@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fevse/songlib/internal/config"
+	"github.com/fevse/songlib/internal/storage"
+)
+
+const migrateUsage = "usage: songlib migrate up|down|status|redo|create NAME"
+
+// migrate implements the "songlib migrate" subcommand.
+func migrate(conf *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	if conf.DBDriver == "memory" {
+		return errors.New("in-memory storage has no migrations")
+	}
+
+	command := args[0]
+	switch command {
+	case "create":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		return storage.CreateMigration(conf.DBDriver, args[1])
+	case "up", "down", "status", "redo":
+	default:
+		return fmt.Errorf("unknown command %q, %s", command, migrateUsage)
+	}
+
+	db, err := openDB(conf)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return storage.NewStorage(db, conf.DBDriver).RunMigrations(context.Background(), command)
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"context"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+func (s *SongLibApp) CreateAlbum(ctx context.Context, album *storage.Album) error {
+	if err := validateAlbum(album); err != nil {
+		return err
+	}
+	if err := normalizeDate(&album.ReleaseDate, &album.ReleaseDatePrecision); err != nil {
+		return err
+	}
+	return referenceError(s.storage.CreateAlbum(ctx, album))
+}
+
+func (s *SongLibApp) GetAlbum(ctx context.Context, id int) (*storage.Album, error) {
+	return s.storage.GetAlbum(ctx, id)
+}
+
+func (s *SongLibApp) UpdateAlbum(ctx context.Context, album *storage.Album) error {
+	if err := validateAlbum(album); err != nil {
+		return err
+	}
+	if err := normalizeDate(&album.ReleaseDate, &album.ReleaseDatePrecision); err != nil {
+		return err
+	}
+	return referenceError(s.storage.UpdateAlbum(ctx, album))
+}
+
+func (s *SongLibApp) DeleteAlbum(ctx context.Context, id int) error {
+	return s.storage.DeleteAlbum(ctx, id)
+}
+
+func (s *SongLibApp) GetAlbums(ctx context.Context, q storage.AlbumQuery) (*storage.AlbumPage, error) {
+	return s.storage.GetAlbums(ctx, q)
+}
+
+// SetTracks replaces the track listing of the album and returns the
+// album with its new tracks.
+func (s *SongLibApp) SetTracks(ctx context.Context, albumID int, tracks []storage.Track) (*storage.Album, error) {
+	if err := normalizeTracks(tracks); err != nil {
+		return nil, err
+	}
+	album, err := s.storage.SetTracks(ctx, albumID, tracks)
+	return album, referenceError(err)
+}
+
+func (s *SongLibApp) RemoveTrack(ctx context.Context, albumID, songID int) error {
+	return s.storage.RemoveTrack(ctx, albumID, songID)
+}
@@ -1,58 +1,222 @@
 package app
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"regexp"
+	"strings"
 
 	"github.com/fevse/songlib/internal/storage"
 )
 
 type SongLibApp struct {
-	storage *storage.Storage
+	storage storage.Repository
 	miURL   string
 }
 
-func NewSongLibApp(stor *storage.Storage, miURL string) *SongLibApp {
+func NewSongLibApp(stor storage.Repository, miURL string) *SongLibApp {
 	return &SongLibApp{storage: stor, miURL: miURL}
 }
 
-func (s *SongLibApp) CreateSong(song *storage.Song) error {
-	detail, err := s.getSongDetails(song.Group, song.Song)
+// OnConflict tells CreateSong what to do when a song with the same band
+// and title already exists. Without it the song is created anyway, unless
+// the storage keeps songs unique and fails with storage.ErrConflict.
+type OnConflict string
+
+const (
+	// OnConflictError fails with storage.ErrConflict.
+	OnConflictError OnConflict = "error"
+	// OnConflictIgnore keeps the existing song and returns it.
+	OnConflictIgnore OnConflict = "ignore"
+	// OnConflictUpdate overwrites the existing song with the new one.
+	OnConflictUpdate OnConflict = "update"
+)
+
+// CreateSong adds the song and reports whether it was created. With
+// OnConflictIgnore or OnConflictUpdate an existing song with the same band
+// and title is returned in song instead, so that a create can be retried.
+func (s *SongLibApp) CreateSong(ctx context.Context, song *storage.Song, onConflict OnConflict) (bool, error) {
+	if err := validateSong(song); err != nil {
+		return false, err
+	}
+	// Songs are put on albums with SetTracks and tagged with AddTags.
+	song.AlbumID, song.DiscNumber, song.TrackNumber = 0, 0, 0
+	song.Tags = nil
+
+	if song.Group == "" {
+		// The artist name is needed to look up song details.
+		artist, err := s.storage.GetArtist(ctx, song.ArtistID)
+		if errors.Is(err, storage.ErrNotFound) {
+			return false, referenceError(storage.ErrUnknownArtist)
+		} else if err != nil {
+			return false, err
+		}
+		song.Group = artist.Name
+	}
+
+	if onConflict == OnConflictIgnore || onConflict == OnConflictError {
+		// A retry does not look up the song details again.
+		if resolved, err := s.resolveConflict(ctx, song, onConflict); resolved {
+			return false, err
+		}
+	}
+
+	detail, err := s.getSongDetails(ctx, song.Group, song.Song)
 	if err != nil {
 		log.Printf("Error a song details: %v", err)
-		// return err
+	} else {
+		song.ReleaseDate = detail.ReleaseDate
+		song.ReleaseDatePrecision = ""
+		song.Text = detail.Text
+		song.Link = detail.Link
 	}
 
-	song.ReleaseDate = detail.ReleaseDate
-	song.Text = detail.Text
-	song.Link = detail.Link
+	if err := normalizeReleaseDate(song); err != nil {
+		log.Printf("Error parsing release date %q: %v", song.ReleaseDate, err)
+		song.ReleaseDate = ""
+		song.ReleaseDatePrecision = ""
+	}
 
-	if err := s.storage.Create(song); err != nil {
+	if onConflict == OnConflictUpdate {
+		if resolved, err := s.resolveConflict(ctx, song, onConflict); resolved {
+			return false, err
+		}
+	}
+
+	artistID := song.ArtistID
+	err = s.storage.Create(ctx, song)
+	if errors.Is(err, storage.ErrConflict) && onConflict != "" {
+		// The song was created by another request in the meantime. Create
+		// may have linked the song to an artist it rolled back.
+		song.ArtistID = artistID
+		if resolved, err := s.resolveConflict(ctx, song, onConflict); resolved {
+			return false, err
+		}
+	}
+	if err != nil {
 		log.Printf("Error creating song: %v", err)
+		return false, referenceError(err)
+	}
+
+	return true, nil
+}
+
+// resolveConflict looks up an existing song with the band and title of the
+// song and handles it as onConflict says. It reports whether there was
+// one, the song is not to be created then.
+func (s *SongLibApp) resolveConflict(ctx context.Context, song *storage.Song, onConflict OnConflict) (bool, error) {
+	existing, err := s.storage.GetByName(ctx, song.Group, song.Song)
+	if errors.Is(err, storage.ErrNotFound) {
+		return false, nil
+	} else if err != nil {
+		return true, err
+	}
+
+	switch onConflict {
+	case OnConflictIgnore:
+		*song = *existing
+		return true, nil
+	case OnConflictUpdate:
+		song.ID = existing.ID
+		return true, referenceError(s.storage.Update(ctx, song))
+	}
+	return true, &storage.ConflictError{Constraint: "songs_keys_key"}
+}
+
+func (s *SongLibApp) GetSong(ctx context.Context, id int) (*storage.Song, error) {
+	return s.storage.GetByID(ctx, id)
+}
+
+func (s *SongLibApp) UpdateSong(ctx context.Context, song *storage.Song) error {
+	if err := validateSong(song); err != nil {
 		return err
 	}
 
+	updated, err := s.storage.UpdateFunc(ctx, song.ID, func(stored *storage.Song) error {
+		dropStalePrecision(song, stored)
+		if err := normalizeReleaseDate(song); err != nil {
+			return err
+		}
+		*stored = *song
+		return nil
+	})
+	if err != nil {
+		return referenceError(err)
+	}
+	*song = *updated
 	return nil
 }
 
-func (s *SongLibApp) UpdateSong(song *storage.Song) error {
-	return s.storage.Update(song)
+func (s *SongLibApp) DeleteSong(ctx context.Context, id int) error {
+	return s.storage.Delete(ctx, id)
+}
+
+func (s *SongLibApp) GetSongs(ctx context.Context, q storage.ListQuery) (*storage.SongPage, error) {
+	return s.storage.GetList(ctx, q)
+}
+
+func (s *SongLibApp) GetSongText(ctx context.Context, id, page, perPage int) (*storage.SongText, error) {
+	song, err := s.storage.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	verses := splitVerses(song.Text)
+	text := &storage.SongText{
+		ID:      song.ID,
+		Group:   song.Group,
+		Song:    song.Song,
+		Page:    page,
+		PerPage: perPage,
+		Total:   len(verses),
+		Verses:  []string{},
+	}
+
+	// Pages are compared before multiplying, a huge page would overflow.
+	if page <= pageCount(len(verses), perPage) {
+		start := (page - 1) * perPage
+		end := min(start+perPage, len(verses))
+		text.Verses = verses[start:end]
+	}
+
+	return text, nil
 }
 
-func (s *SongLibApp) DeleteSong(id int) error {
-	return s.storage.Delete(id)
+// pageCount returns the number of pages of perPage items that total items
+// take.
+func pageCount(total, perPage int) int {
+	return (total + perPage - 1) / perPage
 }
 
-func (s *SongLibApp) GetSongs(filter map[string]string, limit, offset int) ([]storage.Song, error) {
-	return s.storage.GetList(filter, limit, offset)
+var verseSeparator = regexp.MustCompile(`\n[ \t]*\n`)
+
+func splitVerses(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var verses []string
+	for _, verse := range verseSeparator.Split(text, -1) {
+		verse = strings.TrimSpace(verse)
+		if verse != "" {
+			verses = append(verses, verse)
+		}
+	}
+	return verses
 }
 
-func (s *SongLibApp) getSongDetails(group, song string) (*storage.SongDetail, error) {
+func (s *SongLibApp) getSongDetails(ctx context.Context, group, song string) (*storage.SongDetail, error) {
 	url := fmt.Sprintf("%s/info?group=%s&song=%s", s.miURL, group, song)
 
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		log.Printf("Error creating request: %v", err)
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		log.Printf("Error getting song details: %v", err)
 		return &storage.SongDetail{}, err
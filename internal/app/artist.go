@@ -0,0 +1,45 @@
+package app
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+func (s *SongLibApp) CreateArtist(ctx context.Context, artist *storage.Artist) error {
+	if err := validateArtist(artist); err != nil {
+		return err
+	}
+	return s.storage.CreateArtist(ctx, artist)
+}
+
+func (s *SongLibApp) GetArtist(ctx context.Context, id int) (*storage.Artist, error) {
+	return s.storage.GetArtist(ctx, id)
+}
+
+func (s *SongLibApp) UpdateArtist(ctx context.Context, artist *storage.Artist) error {
+	if err := validateArtist(artist); err != nil {
+		return err
+	}
+	return s.storage.UpdateArtist(ctx, artist)
+}
+
+func (s *SongLibApp) DeleteArtist(ctx context.Context, id int) error {
+	return s.storage.DeleteArtist(ctx, id)
+}
+
+func (s *SongLibApp) GetArtists(ctx context.Context, q storage.ArtistQuery) (*storage.ArtistPage, error) {
+	return s.storage.GetArtists(ctx, q)
+}
+
+// GetArtistSongs lists the songs of the artist, q is narrowed down to
+// the artist.
+func (s *SongLibApp) GetArtistSongs(ctx context.Context, id int, q storage.ListQuery) (*storage.SongPage, error) {
+	cond, err := storage.NewCondition("artistId", storage.OpEq, strconv.Itoa(id))
+	if err != nil {
+		return nil, err
+	}
+	q.Filter = append(q.Filter, cond)
+	return s.storage.GetList(ctx, q)
+}
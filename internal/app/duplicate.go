@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+func (s *SongLibApp) Duplicates(ctx context.Context, q storage.DuplicateQuery) (*storage.DuplicatePage, error) {
+	return s.storage.Duplicates(ctx, q)
+}
+
+// MergeSongs folds the duplicates into the song and returns the merged
+// song. Requests for a duplicate are redirected to it afterwards.
+func (s *SongLibApp) MergeSongs(ctx context.Context, id int, merge storage.SongMerge) (*storage.Song, error) {
+	var errs ValidationErrors
+	if len(merge.Duplicates) == 0 {
+		errs.add("duplicates", "is required")
+	}
+	for _, dupID := range merge.Duplicates {
+		if dupID == id {
+			errs.add("duplicates", "song cannot be merged into itself")
+			break
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	slices.Sort(merge.Duplicates)
+
+	song, err := s.storage.Merge(ctx, id, slices.Compact(merge.Duplicates), merge.Force)
+	if errors.Is(err, storage.ErrUnknownSong) {
+		return nil, ValidationErrors{{Field: "duplicates", Message: err.Error()}}
+	}
+	return song, err
+}
+
+// SongRedirect returns the song a merged duplicate id now refers to.
+func (s *SongLibApp) SongRedirect(ctx context.Context, id int) (int, error) {
+	return s.storage.Redirect(ctx, id)
+}
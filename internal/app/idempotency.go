@@ -0,0 +1,21 @@
+package app
+
+import (
+	"context"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+// ReserveIdempotencyKey reserves the key for a request, or returns the
+// request saved with it earlier.
+func (s *SongLibApp) ReserveIdempotencyKey(ctx context.Context, rec storage.IdempotencyRecord) (*storage.IdempotencyRecord, error) {
+	return s.storage.ReserveKey(ctx, rec)
+}
+
+func (s *SongLibApp) SaveIdempotentResponse(ctx context.Context, rec storage.IdempotencyRecord) error {
+	return s.storage.SaveResponse(ctx, rec)
+}
+
+func (s *SongLibApp) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	return s.storage.ReleaseKey(ctx, key)
+}
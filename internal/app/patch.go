@@ -0,0 +1,390 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+type PatchType int
+
+const (
+	// MergePatch is JSON Merge Patch, RFC 7396.
+	MergePatch PatchType = iota
+	// JSONPatch is JSON Patch, RFC 6902.
+	JSONPatch
+)
+
+var (
+	ErrInvalidPatch    = errors.New("invalid patch")
+	ErrPatchTestFailed = errors.New("patch test failed")
+)
+
+func patchError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidPatch, fmt.Sprintf(format, args...))
+}
+
+func (s *SongLibApp) PatchSong(ctx context.Context, id int, typ PatchType, patch []byte) (*storage.Song, error) {
+	song, err := s.storage.UpdateFunc(ctx, id, func(song *storage.Song) error {
+		patched, err := applyPatch(song, typ, patch)
+		if err != nil {
+			return err
+		}
+		if patched.Group != song.Group && patched.ArtistID == song.ArtistID {
+			// A new group moves the song to the artist it names.
+			patched.ArtistID = 0
+		}
+		if !patchesField(typ, patch, "releaseDatePrecision") {
+			dropStalePrecision(patched, song)
+		}
+		if err := validateSong(patched); err != nil {
+			return err
+		}
+		if err := normalizeReleaseDate(patched); err != nil {
+			return err
+		}
+
+		*song = *patched
+		return nil
+	})
+	return song, referenceError(err)
+}
+
+func applyPatch(song *storage.Song, typ PatchType, patch []byte) (*storage.Song, error) {
+	data, err := json.Marshal(song)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	switch typ {
+	case MergePatch:
+		var p any
+		if err := json.Unmarshal(patch, &p); err != nil {
+			return nil, patchError("%v", err)
+		}
+		if _, ok := p.(map[string]any); !ok {
+			return nil, patchError("merge patch must be a JSON object")
+		}
+		doc = mergePatch(doc, p)
+	case JSONPatch:
+		var ops []patchOperation
+		if err := json.Unmarshal(patch, &ops); err != nil {
+			return nil, patchError("%v", err)
+		}
+		for i, op := range ops {
+			if doc, err = op.apply(doc); err != nil {
+				return nil, fmt.Errorf("operation %d: %w", i, err)
+			}
+		}
+	default:
+		return nil, patchError("unsupported patch type")
+	}
+
+	if data, err = json.Marshal(doc); err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var patched storage.Song
+	if err := dec.Decode(&patched); err != nil {
+		return nil, patchError("%v", err)
+	}
+	if patched.ID != song.ID {
+		return nil, patchError("id cannot be changed")
+	}
+	return &patched, nil
+}
+
+// patchesField reports whether the patch writes the top-level field of
+// the song.
+func patchesField(typ PatchType, patch []byte, field string) bool {
+	switch typ {
+	case MergePatch:
+		var p map[string]json.RawMessage
+		if err := json.Unmarshal(patch, &p); err == nil {
+			_, ok := p[field]
+			return ok
+		}
+	case JSONPatch:
+		var ops []patchOperation
+		if err := json.Unmarshal(patch, &ops); err == nil {
+			for _, op := range ops {
+				if op.Op != "test" && op.Path != nil && *op.Path == "/"+field {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+	for key, value := range p {
+		if value == nil {
+			delete(t, key)
+		} else {
+			t[key] = mergePatch(t[key], value)
+		}
+	}
+	return t
+}
+
+type patchOperation struct {
+	Op    string           `json:"op"`
+	Path  *string          `json:"path"`
+	From  *string          `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+func (op patchOperation) apply(doc any) (any, error) {
+	if op.Path == nil {
+		return nil, patchError("missing path")
+	}
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		switch op.Op {
+		case "add":
+			return addValue(doc, path, value)
+		case "replace":
+			return replaceValue(doc, path, value)
+		}
+		current, err := getValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(current, value) {
+			return nil, fmt.Errorf("%w: value at %q differs", ErrPatchTestFailed, *op.Path)
+		}
+		return doc, nil
+	case "remove":
+		return removeValue(doc, path)
+	case "move", "copy":
+		if op.From == nil {
+			return nil, patchError("missing from")
+		}
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := getValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if len(from) < len(path) && reflect.DeepEqual(from, path[:len(from)]) {
+				return nil, patchError("cannot move %q into its own child", *op.From)
+			}
+			if doc, err = removeValue(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			value = deepCopy(value)
+		}
+		return addValue(doc, path, value)
+	}
+	return nil, patchError("unknown operation %q", op.Op)
+}
+
+func (op patchOperation) value() (any, error) {
+	if op.Value == nil {
+		return nil, patchError("missing value")
+	}
+
+	var value any
+	if err := json.Unmarshal(*op.Value, &value); err != nil {
+		return nil, patchError("%v", err)
+	}
+	return value, nil
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, patchError("invalid pointer %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func getValue(doc any, path []string) (any, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, patchError("path %q not found", token)
+			}
+			doc = value
+		case []any:
+			i, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, patchError("path %q not found", token)
+		}
+	}
+	return doc, nil
+}
+
+// modify walks to the parent of the last token, lets fn change it and
+// rebuilds the document on the way back, since arrays may be reallocated.
+func modify(doc any, path []string, fn func(parent any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+
+	child, err := getValue(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	child, err = modify(child, path[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch node := doc.(type) {
+	case map[string]any:
+		node[path[0]] = child
+	case []any:
+		i, _ := arrayIndex(path[0], len(node)-1)
+		node[i] = child
+	}
+	return doc, nil
+}
+
+func addValue(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return modify(doc, path, func(parent any, token string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			node[token] = value
+			return node, nil
+		case []any:
+			if token == "-" {
+				return append(node, value), nil
+			}
+			i, err := arrayIndex(token, len(node))
+			if err != nil {
+				return nil, err
+			}
+			return append(node[:i], append([]any{value}, node[i:]...)...), nil
+		}
+		return nil, patchError("path %q not found", token)
+	})
+}
+
+func removeValue(doc any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, patchError("cannot remove the whole document")
+	}
+
+	return modify(doc, path, func(parent any, token string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			if _, ok := node[token]; !ok {
+				return nil, patchError("path %q not found", token)
+			}
+			delete(node, token)
+			return node, nil
+		case []any:
+			i, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			return append(node[:i], node[i+1:]...), nil
+		}
+		return nil, patchError("path %q not found", token)
+	})
+}
+
+func replaceValue(doc any, path []string, value any) (any, error) {
+	if _, err := getValue(doc, path); err != nil {
+		return nil, err
+	}
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return modify(doc, path, func(parent any, token string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			node[token] = value
+			return node, nil
+		case []any:
+			i, _ := arrayIndex(token, len(node)-1)
+			node[i] = value
+			return node, nil
+		}
+		return nil, patchError("path %q not found", token)
+	})
+}
+
+func arrayIndex(token string, last int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > last || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, patchError("invalid array index %q", token)
+	}
+	return i, nil
+}
+
+func deepCopy(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(v))
+		for key, item := range v {
+			c[key] = deepCopy(item)
+		}
+		return c
+	case []any:
+		c := make([]any, len(v))
+		for i, item := range v {
+			c[i] = deepCopy(item)
+		}
+		return c
+	}
+	return value
+}
@@ -0,0 +1,211 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+func TestApplyPatch(t *testing.T) {
+	song := storage.Song{
+		ID: 1, Group: "Muse", Song: "Hole", ReleaseDate: "2006-07-16", ReleaseDatePrecision: "day",
+		Text: "lyrics", Link: "https://example.com", Tags: []string{"alt", "rock"},
+	}
+	tests := []struct {
+		name  string
+		typ   PatchType
+		patch string
+		want  func(s *storage.Song)
+	}{
+		{"merge sets a field", MergePatch, `{"song": "Uprising"}`,
+			func(s *storage.Song) { s.Song = "Uprising" }},
+		{"merge null clears a field", MergePatch, `{"link": null, "text": "new"}`,
+			func(s *storage.Song) { s.Link, s.Text = "", "new" }},
+		{"merge empty object", MergePatch, `{}`,
+			func(s *storage.Song) {}},
+		{"merge replaces arrays", MergePatch, `{"tags": ["pop"]}`,
+			func(s *storage.Song) { s.Tags = []string{"pop"} }},
+		{"replace", JSONPatch, `[{"op": "replace", "path": "/group", "value": "MUSE"}]`,
+			func(s *storage.Song) { s.Group = "MUSE" }},
+		{"add", JSONPatch, `[{"op": "add", "path": "/text", "value": "new"}]`,
+			func(s *storage.Song) { s.Text = "new" }},
+		{"remove", JSONPatch, `[{"op": "remove", "path": "/link"}]`,
+			func(s *storage.Song) { s.Link = "" }},
+		{"add to array", JSONPatch, `[{"op": "add", "path": "/tags/1", "value": "indie"}, {"op": "add", "path": "/tags/-", "value": "uk"}]`,
+			func(s *storage.Song) { s.Tags = []string{"alt", "indie", "rock", "uk"} }},
+		{"remove from array", JSONPatch, `[{"op": "remove", "path": "/tags/0"}]`,
+			func(s *storage.Song) { s.Tags = []string{"rock"} }},
+		{"move", JSONPatch, `[{"op": "move", "from": "/text", "path": "/link"}]`,
+			func(s *storage.Song) { s.Link, s.Text = "lyrics", "" }},
+		{"copy", JSONPatch, `[{"op": "copy", "from": "/song", "path": "/text"}]`,
+			func(s *storage.Song) { s.Text = "Hole" }},
+		{"test then replace", JSONPatch, `[{"op": "test", "path": "/song", "value": "Hole"}, {"op": "replace", "path": "/song", "value": "Starlight"}]`,
+			func(s *storage.Song) { s.Song = "Starlight" }},
+		{"test passes", JSONPatch, `[{"op": "test", "path": "/tags/0", "value": "alt"}]`,
+			func(s *storage.Song) {}},
+	}
+	for _, tt := range tests {
+		want := song
+		want.Tags = append([]string(nil), song.Tags...)
+		tt.want(&want)
+
+		got, err := applyPatch(&song, tt.typ, []byte(tt.patch))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(*got, want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, *got, want)
+		}
+	}
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	song := storage.Song{ID: 1, Group: "Muse", Song: "Hole", Tags: []string{"rock"}}
+	tests := []struct {
+		name  string
+		typ   PatchType
+		patch string
+		want  error
+	}{
+		{"merge not JSON", MergePatch, `{`, ErrInvalidPatch},
+		{"merge not an object", MergePatch, `["song"]`, ErrInvalidPatch},
+		{"merge unknown field", MergePatch, `{"genre": "rock"}`, ErrInvalidPatch},
+		{"merge wrong type", MergePatch, `{"song": 1}`, ErrInvalidPatch},
+		{"merge changes id", MergePatch, `{"id": 2}`, ErrInvalidPatch},
+		{"not an array", JSONPatch, `{"op": "add"}`, ErrInvalidPatch},
+		{"missing path", JSONPatch, `[{"op": "add", "value": 1}]`, ErrInvalidPatch},
+		{"missing value", JSONPatch, `[{"op": "add", "path": "/text"}]`, ErrInvalidPatch},
+		{"missing from", JSONPatch, `[{"op": "move", "path": "/text"}]`, ErrInvalidPatch},
+		{"unknown operation", JSONPatch, `[{"op": "swap", "path": "/text"}]`, ErrInvalidPatch},
+		{"invalid pointer", JSONPatch, `[{"op": "remove", "path": "text"}]`, ErrInvalidPatch},
+		{"missing member", JSONPatch, `[{"op": "replace", "path": "/genre", "value": "rock"}]`, ErrInvalidPatch},
+		{"index out of range", JSONPatch, `[{"op": "remove", "path": "/tags/1"}]`, ErrInvalidPatch},
+		{"index with leading zero", JSONPatch, `[{"op": "remove", "path": "/tags/00"}]`, ErrInvalidPatch},
+		{"remove the document", JSONPatch, `[{"op": "remove", "path": ""}]`, ErrInvalidPatch},
+		{"move into own child", JSONPatch, `[{"op": "move", "from": "/tags", "path": "/tags/0"}]`, ErrInvalidPatch},
+		{"test fails", JSONPatch, `[{"op": "test", "path": "/song", "value": "Uprising"}]`, ErrPatchTestFailed},
+		{"unsupported type", PatchType(7), `{}`, ErrInvalidPatch},
+	}
+	for _, tt := range tests {
+		if _, err := applyPatch(&song, tt.typ, []byte(tt.patch)); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestParsePointer(t *testing.T) {
+	tests := []struct {
+		pointer string
+		want    []string
+	}{
+		{"", nil},
+		{"/", []string{""}},
+		{"/song", []string{"song"}},
+		{"/tags/0", []string{"tags", "0"}},
+		{"/a~1b/c~0d/~01", []string{"a/b", "c~d", "~1"}},
+	}
+	for _, tt := range tests {
+		got, err := parsePointer(tt.pointer)
+		if err != nil {
+			t.Errorf("parsePointer(%q): %v", tt.pointer, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parsePointer(%q) = %q, want %q", tt.pointer, got, tt.want)
+		}
+	}
+}
+
+func TestPatchesField(t *testing.T) {
+	tests := []struct {
+		typ   PatchType
+		patch string
+		want  bool
+	}{
+		{MergePatch, `{"releaseDate": "2007"}`, false},
+		{MergePatch, `{"releaseDate": "2007", "releaseDatePrecision": "year"}`, true},
+		{MergePatch, `{"releaseDatePrecision": null}`, true},
+		{JSONPatch, `[{"op": "replace", "path": "/releaseDate", "value": "2007"}]`, false},
+		{JSONPatch, `[{"op": "test", "path": "/releaseDatePrecision", "value": "year"}]`, false},
+		{JSONPatch, `[{"op": "remove", "path": "/releaseDatePrecision"}]`, true},
+		{JSONPatch, `[{"op": "copy", "from": "/text", "path": "/releaseDatePrecision"}]`, true},
+	}
+	for _, tt := range tests {
+		if got := patchesField(tt.typ, []byte(tt.patch), "releaseDatePrecision"); got != tt.want {
+			t.Errorf("patchesField(%s) = %v, want %v", tt.patch, got, tt.want)
+		}
+	}
+}
+
+// newTestApp stores the song in a memory storage, without looking up its
+// details.
+func newTestApp(t *testing.T, song *storage.Song) *SongLibApp {
+	t.Helper()
+	stor := storage.NewMemoryStorage()
+	if err := stor.Create(context.Background(), song); err != nil {
+		t.Fatal(err)
+	}
+	return NewSongLibApp(stor, "")
+}
+
+func TestPatchSongReleaseDate(t *testing.T) {
+	tests := []struct {
+		name          string
+		stored        string
+		precision     string
+		typ           PatchType
+		patch         string
+		wantDate      string
+		wantPrecision string
+	}{
+		{"new full date on a year", "2007-01-01", "year", MergePatch, `{"releaseDate": "2007-03-04"}`, "2007-03-04", "day"},
+		{"new month on a day", "2007-03-04", "day", MergePatch, `{"releaseDate": "2008-05"}`, "2008-05-01", "month"},
+		{"explicit precision", "2007-01-01", "year", MergePatch, `{"releaseDate": "2007-03-04", "releaseDatePrecision": "year"}`, "2007-01-01", "year"},
+		{"other field", "2007-01-01", "year", MergePatch, `{"text": "lyrics"}`, "2007-01-01", "year"},
+		{"cleared date", "2007-01-01", "year", MergePatch, `{"releaseDate": null}`, "", ""},
+		{"JSON Patch date", "2007-01-01", "year", JSONPatch, `[{"op": "replace", "path": "/releaseDate", "value": "04.03.2007"}]`, "2007-03-04", "day"},
+		{"JSON Patch precision", "2007-03-04", "day", JSONPatch, `[{"op": "replace", "path": "/releaseDatePrecision", "value": "month"}]`, "2007-03-01", "month"},
+	}
+	for _, tt := range tests {
+		song := storage.Song{Group: "Muse", Song: "Hole", ReleaseDate: tt.stored, ReleaseDatePrecision: tt.precision}
+		a := newTestApp(t, &song)
+
+		got, err := a.PatchSong(context.Background(), song.ID, tt.typ, []byte(tt.patch))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got.ReleaseDate != tt.wantDate || got.ReleaseDatePrecision != tt.wantPrecision {
+			t.Errorf("%s: release date = %q %q, want %q %q",
+				tt.name, got.ReleaseDate, got.ReleaseDatePrecision, tt.wantDate, tt.wantPrecision)
+		}
+	}
+}
+
+func TestPatchSongValidation(t *testing.T) {
+	song := storage.Song{Group: "Muse", Song: "Hole"}
+	a := newTestApp(t, &song)
+
+	var verrs ValidationErrors
+	if _, err := a.PatchSong(context.Background(), song.ID, MergePatch, []byte(`{"song": ""}`)); !errors.As(err, &verrs) {
+		t.Errorf("empty song: err = %v, want ValidationErrors", err)
+	}
+	if _, err := a.PatchSong(context.Background(), song.ID, MergePatch, []byte(`{"releaseDate": "someday"}`)); err == nil {
+		t.Error("invalid date: patch succeeded")
+	}
+	if _, err := a.PatchSong(context.Background(), song.ID+1, MergePatch, []byte(`{}`)); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("missing song: err = %v, want ErrNotFound", err)
+	}
+
+	got, err := a.GetSong(context.Background(), song.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Song != "Hole" || got.ReleaseDate != "" {
+		t.Errorf("failed patches changed the song: %+v", got)
+	}
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+func (s *SongLibApp) CreatePlaylist(ctx context.Context, playlist *storage.Playlist) error {
+	if err := validatePlaylist(playlist); err != nil {
+		return err
+	}
+	return s.storage.CreatePlaylist(ctx, playlist)
+}
+
+func (s *SongLibApp) GetPlaylist(ctx context.Context, id int) (*storage.Playlist, error) {
+	return s.storage.GetPlaylist(ctx, id)
+}
+
+func (s *SongLibApp) UpdatePlaylist(ctx context.Context, playlist *storage.Playlist) error {
+	if err := validatePlaylist(playlist); err != nil {
+		return err
+	}
+	return s.storage.UpdatePlaylist(ctx, playlist)
+}
+
+func (s *SongLibApp) DeletePlaylist(ctx context.Context, id int) error {
+	return s.storage.DeletePlaylist(ctx, id)
+}
+
+func (s *SongLibApp) GetPlaylists(ctx context.Context, q storage.PlaylistQuery) (*storage.PlaylistPage, error) {
+	return s.storage.GetPlaylists(ctx, q)
+}
+
+// InsertEntry puts a song into the playlist and returns the playlist with
+// its new entries.
+func (s *SongLibApp) InsertEntry(ctx context.Context, playlistID int, insert storage.PlaylistInsert) (*storage.Playlist, error) {
+	var errs ValidationErrors
+	if insert.SongID <= 0 {
+		errs.add("songId", "is required")
+	}
+	if insert.Position < 0 {
+		errs.add("position", "must be positive")
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	playlist, err := s.storage.InsertEntry(ctx, playlistID, insert)
+	return playlist, playlistError(err)
+}
+
+// MoveEntry moves an entry of the playlist and returns the playlist with
+// its entries in the new order.
+func (s *SongLibApp) MoveEntry(ctx context.Context, playlistID, entryID int, move storage.PlaylistMove) (*storage.Playlist, error) {
+	if move.Position <= 0 {
+		return nil, ValidationErrors{{Field: "position", Message: "is required"}}
+	}
+
+	playlist, err := s.storage.MoveEntry(ctx, playlistID, entryID, move)
+	return playlist, playlistError(err)
+}
+
+func (s *SongLibApp) RemoveEntry(ctx context.Context, playlistID, entryID int) error {
+	return s.storage.RemoveEntry(ctx, playlistID, entryID)
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+type DatePrecision string
+
+const (
+	PrecisionDay   DatePrecision = "day"
+	PrecisionMonth DatePrecision = "month"
+	PrecisionYear  DatePrecision = "year"
+)
+
+// isoDate is how release dates are stored and returned.
+const isoDate = "2006-01-02"
+
+var ErrInvalidDate = errors.New("unrecognized date format")
+
+type ReleaseDate struct {
+	Time      time.Time
+	Precision DatePrecision
+}
+
+// String returns the date in ISO format, dates with month or year
+// precision point to the first day of the period.
+func (d ReleaseDate) String() string {
+	return d.Time.Format(isoDate)
+}
+
+// End returns the start of the period following the date: the next day,
+// month or year depending on the precision.
+func (d ReleaseDate) End() ReleaseDate {
+	switch d.Precision {
+	case PrecisionMonth:
+		d.Time = d.Time.AddDate(0, 1, 0)
+	case PrecisionYear:
+		d.Time = d.Time.AddDate(1, 0, 0)
+	default:
+		d.Time = d.Time.AddDate(0, 0, 1)
+	}
+	return d
+}
+
+var dateLayouts = []struct {
+	layout    string
+	precision DatePrecision
+}{
+	{"02.01.2006", PrecisionDay},
+	{"2.1.2006", PrecisionDay},
+	{"2006-01-02", PrecisionDay},
+	{time.RFC3339, PrecisionDay},
+	{"2006/01/02", PrecisionDay},
+	{"2 January 2006", PrecisionDay},
+	{"2 Jan 2006", PrecisionDay},
+	{"January 2, 2006", PrecisionDay},
+	{"Jan 2, 2006", PrecisionDay},
+	{"01.2006", PrecisionMonth},
+	{"2006-01", PrecisionMonth},
+	{"January 2006", PrecisionMonth},
+	{"Jan 2006", PrecisionMonth},
+	{"2006", PrecisionYear},
+}
+
+// ParseReleaseDate accepts the date formats seen in the enrichment API and
+// in client requests: DD.MM.YYYY, ISO dates, month and year only, and
+// English month names. The precision tells which parts were present.
+func ParseReleaseDate(value string) (ReleaseDate, error) {
+	value = strings.TrimSpace(value)
+	for _, l := range dateLayouts {
+		t, err := time.Parse(l.layout, value)
+		if err == nil {
+			return ReleaseDate{
+				Time:      time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC),
+				Precision: l.precision,
+			}, nil
+		}
+	}
+	return ReleaseDate{}, ErrInvalidDate
+}
+
+// truncate drops the parts of the date finer than the precision.
+func (d ReleaseDate) truncate(precision DatePrecision) ReleaseDate {
+	switch precision {
+	case PrecisionMonth:
+		d.Time = time.Date(d.Time.Year(), d.Time.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case PrecisionYear:
+		d.Time = time.Date(d.Time.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return d
+	}
+	d.Precision = precision
+	return d
+}
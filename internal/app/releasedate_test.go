@@ -0,0 +1,153 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+func TestParseReleaseDate(t *testing.T) {
+	tests := []struct {
+		value     string
+		want      string
+		precision DatePrecision
+	}{
+		{"16.07.2006", "2006-07-16", PrecisionDay},
+		{"4.3.2007", "2007-03-04", PrecisionDay},
+		{"2006-07-16", "2006-07-16", PrecisionDay},
+		{"2006-07-16T10:00:00+03:00", "2006-07-16", PrecisionDay},
+		{"2006/07/16", "2006-07-16", PrecisionDay},
+		{"16 July 2006", "2006-07-16", PrecisionDay},
+		{"16 Jul 2006", "2006-07-16", PrecisionDay},
+		{"July 16, 2006", "2006-07-16", PrecisionDay},
+		{"Jul 16, 2006", "2006-07-16", PrecisionDay},
+		{"07.2006", "2006-07-01", PrecisionMonth},
+		{"2006-07", "2006-07-01", PrecisionMonth},
+		{"July 2006", "2006-07-01", PrecisionMonth},
+		{"Jul 2006", "2006-07-01", PrecisionMonth},
+		{"2006", "2006-01-01", PrecisionYear},
+		{" 2006 ", "2006-01-01", PrecisionYear},
+	}
+	for _, tt := range tests {
+		got, err := ParseReleaseDate(tt.value)
+		if err != nil {
+			t.Errorf("ParseReleaseDate(%q): %v", tt.value, err)
+			continue
+		}
+		if got.String() != tt.want || got.Precision != tt.precision {
+			t.Errorf("ParseReleaseDate(%q) = %s %s, want %s %s", tt.value, got, got.Precision, tt.want, tt.precision)
+		}
+	}
+}
+
+func TestParseReleaseDateInvalid(t *testing.T) {
+	for _, value := range []string{"", "yesterday", "31.02.2006", "2006-13", "16/07/2006", "summer 2006"} {
+		if got, err := ParseReleaseDate(value); !errors.Is(err, ErrInvalidDate) {
+			t.Errorf("ParseReleaseDate(%q) = %s, %v, want ErrInvalidDate", value, got, err)
+		}
+	}
+}
+
+func TestReleaseDateEnd(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"2006-07-16", "2006-07-17"},
+		{"2006-12-31", "2007-01-01"},
+		{"2006-07", "2006-08-01"},
+		{"2006-12", "2007-01-01"},
+		{"2006", "2007-01-01"},
+	}
+	for _, tt := range tests {
+		date, err := ParseReleaseDate(tt.value)
+		if err != nil {
+			t.Fatalf("ParseReleaseDate(%q): %v", tt.value, err)
+		}
+		if got := date.End().String(); got != tt.want {
+			t.Errorf("End of %q = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeDate(t *testing.T) {
+	tests := []struct {
+		value, precision         string
+		wantValue, wantPrecision string
+	}{
+		{"", "year", "", ""},
+		{"16.07.2006", "", "2006-07-16", "day"},
+		{"2006", "", "2006-01-01", "year"},
+		// A coarser precision sent with a full date is kept.
+		{"2006-07-16", "month", "2006-07-01", "month"},
+		{"2006-01-01", "year", "2006-01-01", "year"},
+		// A finer precision than the date has is not.
+		{"2006", "day", "2006-01-01", "year"},
+	}
+	for _, tt := range tests {
+		value, precision := tt.value, tt.precision
+		if err := normalizeDate(&value, &precision); err != nil {
+			t.Errorf("normalizeDate(%q, %q): %v", tt.value, tt.precision, err)
+			continue
+		}
+		if value != tt.wantValue || precision != tt.wantPrecision {
+			t.Errorf("normalizeDate(%q, %q) = %q, %q, want %q, %q",
+				tt.value, tt.precision, value, precision, tt.wantValue, tt.wantPrecision)
+		}
+	}
+}
+
+func TestDropStalePrecision(t *testing.T) {
+	stored := storage.Song{ReleaseDate: "2007-01-01", ReleaseDatePrecision: "year"}
+	tests := []struct {
+		date, precision string
+		want            string
+	}{
+		// The date is unchanged, in any format.
+		{"2007-01-01", "year", "year"},
+		{"2007", "year", "year"},
+		{"01.01.2007", "year", "year"},
+		// A new date with the stored precision takes its own.
+		{"2007-03-04", "year", ""},
+		{"", "year", ""},
+		// A precision that differs from the stored one was set on purpose.
+		{"2007-03-04", "month", "month"},
+		{"2007-03-04", "", ""},
+	}
+	for _, tt := range tests {
+		song := storage.Song{ReleaseDate: tt.date, ReleaseDatePrecision: tt.precision}
+		dropStalePrecision(&song, &stored)
+		if song.ReleaseDatePrecision != tt.want {
+			t.Errorf("dropStalePrecision(%q, %q) precision = %q, want %q", tt.date, tt.precision, song.ReleaseDatePrecision, tt.want)
+		}
+	}
+}
+
+func TestUpdateSongReleaseDate(t *testing.T) {
+	tests := []struct {
+		name               string
+		date, precision    string
+		wantDate, wantPrec string
+	}{
+		{"round trip", "2007-01-01", "year", "2007-01-01", "year"},
+		{"new date with the stored precision", "2007-03-04", "year", "2007-03-04", "day"},
+		{"new date without precision", "2007-03", "", "2007-03-01", "month"},
+		{"coarser precision", "2007-03-04", "month", "2007-03-01", "month"},
+	}
+	for _, tt := range tests {
+		stored := storage.Song{Group: "Muse", Song: "Hole", ReleaseDate: "2007-01-01", ReleaseDatePrecision: "year"}
+		a := newTestApp(t, &stored)
+
+		song := storage.Song{ID: stored.ID, Group: "Muse", Song: "Hole", ReleaseDate: tt.date, ReleaseDatePrecision: tt.precision}
+		if err := a.UpdateSong(context.Background(), &song); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if song.ReleaseDate != tt.wantDate || song.ReleaseDatePrecision != tt.wantPrec {
+			t.Errorf("%s: release date = %q %q, want %q %q",
+				tt.name, song.ReleaseDate, song.ReleaseDatePrecision, tt.wantDate, tt.wantPrec)
+		}
+	}
+}
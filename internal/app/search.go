@@ -0,0 +1,46 @@
+package app
+
+import (
+	"context"
+	"log"
+	"slices"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+// maxSuggestions limits the "did you mean" suggestions of a search.
+const maxSuggestions = 5
+
+// SearchSongs runs a full-text search, when nothing is found the page
+// suggests similar band names and titles.
+func (s *SongLibApp) SearchSongs(ctx context.Context, q storage.SearchQuery) (*storage.SearchPage, error) {
+	page, err := s.storage.Search(ctx, q)
+	if err != nil || page.Total > 0 {
+		return page, err
+	}
+
+	// Songs of a band all match its name, fetch more to fill the list.
+	matches, err := s.storage.Fuzzy(ctx, storage.FuzzyQuery{
+		Query:    q.Query,
+		MinScore: storage.DefaultMinScore,
+		Limit:    maxSuggestions * 4,
+	})
+	if err != nil {
+		// The search itself succeeded, suggestions are optional.
+		log.Printf("Error getting suggestions: %v", err)
+		return page, nil
+	}
+	for _, m := range matches.Items {
+		if len(page.Suggestions) == maxSuggestions {
+			break
+		}
+		if !slices.Contains(page.Suggestions, m.Match) {
+			page.Suggestions = append(page.Suggestions, m.Match)
+		}
+	}
+	return page, nil
+}
+
+func (s *SongLibApp) FuzzySongs(ctx context.Context, q storage.FuzzyQuery) (*storage.FuzzyPage, error) {
+	return s.storage.Fuzzy(ctx, q)
+}
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"context"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+// AddTags tags the song and returns it with all its tags.
+func (s *SongLibApp) AddTags(ctx context.Context, songID int, tags []storage.Tag) (*storage.Song, error) {
+	if err := validateTags(tags); err != nil {
+		return nil, err
+	}
+	if err := s.storage.AddTags(ctx, songID, tags); err != nil {
+		return nil, err
+	}
+	return s.storage.GetByID(ctx, songID)
+}
+
+// RemoveTags removes the tags from the song and returns it with the tags
+// left.
+func (s *SongLibApp) RemoveTags(ctx context.Context, songID int, names []string) (*storage.Song, error) {
+	if err := s.storage.RemoveTags(ctx, songID, names); err != nil {
+		return nil, err
+	}
+	return s.storage.GetByID(ctx, songID)
+}
+
+func (s *SongLibApp) GetFacets(ctx context.Context, q storage.ListQuery, kind string) (*storage.Facets, error) {
+	return s.storage.Facets(ctx, q, kind)
+}
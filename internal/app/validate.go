@@ -0,0 +1,253 @@
+package app
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+// maxNameLength matches VARCHAR(255) of songs.band and songs.song.
+const maxNameLength = 255
+
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+// ValidationErrors collects every violation found in a song, so that the
+// client can fix them all at once.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Field + ": " + err.Message
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationErrors) add(field, message string) {
+	*e = append(*e, ValidationError{Field: field, Message: message})
+}
+
+func validateSong(song *storage.Song) error {
+	var errs ValidationErrors
+
+	if song.Group != "" || song.ArtistID == 0 {
+		validateName(&errs, "group", song.Group)
+	}
+	validateName(&errs, "song", song.Song)
+
+	if song.Link != "" {
+		u, err := url.ParseRequestURI(song.Link)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs.add("link", "must be an absolute http or https URL")
+		}
+	}
+
+	validateDate(&errs, song.ReleaseDate, song.ReleaseDatePrecision)
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateDate(errs *ValidationErrors, value, precision string) {
+	if value != "" {
+		if _, err := ParseReleaseDate(value); err != nil {
+			errs.add("releaseDate", "must be a date, e.g. 16.07.2006, 2006-07-16, 2006-07 or 2006")
+		}
+	}
+
+	switch DatePrecision(precision) {
+	case "", PrecisionDay, PrecisionMonth, PrecisionYear:
+	default:
+		errs.add("releaseDatePrecision", "must be one of day, month, year")
+	}
+}
+
+func validateName(errs *ValidationErrors, field, value string) {
+	switch {
+	case strings.TrimSpace(value) == "":
+		errs.add(field, "is required")
+	case utf8.RuneCountInString(value) > maxNameLength:
+		errs.add(field, "must be at most 255 characters long")
+	}
+}
+
+// normalizeReleaseDate converts the release date to ISO format and sets
+// its precision. A coarser precision sent along with a full date is kept,
+// so that dates returned by the API survive a round trip.
+func normalizeReleaseDate(song *storage.Song) error {
+	return normalizeDate(&song.ReleaseDate, &song.ReleaseDatePrecision)
+}
+
+// dropStalePrecision clears the precision of an updated song when its
+// release date moved away from the stored one but the precision was left
+// as stored, it is then taken from the new date instead of truncating it.
+func dropStalePrecision(song, stored *storage.Song) {
+	if song.ReleaseDatePrecision != stored.ReleaseDatePrecision {
+		return
+	}
+	if date, err := ParseReleaseDate(song.ReleaseDate); err == nil && date.String() == stored.ReleaseDate {
+		return
+	}
+	song.ReleaseDatePrecision = ""
+}
+
+func normalizeDate(value, precision *string) error {
+	if *value == "" {
+		*precision = ""
+		return nil
+	}
+
+	date, err := ParseReleaseDate(*value)
+	if err != nil {
+		return err
+	}
+	if date.Precision == PrecisionDay {
+		date = date.truncate(DatePrecision(*precision))
+	}
+
+	*value = date.String()
+	*precision = string(date.Precision)
+	return nil
+}
+
+func validateArtist(artist *storage.Artist) error {
+	var errs ValidationErrors
+	validateName(&errs, "name", artist.Name)
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateAlbum(album *storage.Album) error {
+	var errs ValidationErrors
+	validateName(&errs, "title", album.Title)
+	if album.ArtistID <= 0 {
+		errs.add("artistId", "is required")
+	}
+	validateDate(&errs, album.ReleaseDate, album.ReleaseDatePrecision)
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validatePlaylist(playlist *storage.Playlist) error {
+	var errs ValidationErrors
+	validateName(&errs, "name", playlist.Name)
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Tag names and kinds match VARCHAR(64) and VARCHAR(32) of the tags table.
+const (
+	maxTagLength     = 64
+	maxTagKindLength = 32
+)
+
+func validateTags(tags []storage.Tag) error {
+	var errs ValidationErrors
+	if len(tags) == 0 {
+		errs.add("tags", "at least one tag is required")
+	}
+	for i, tag := range tags {
+		field := "tags[" + strconv.Itoa(i) + "]"
+		switch name := storage.TagName(tag.Name); {
+		case name == "":
+			errs.add(field+".name", "is required")
+		case utf8.RuneCountInString(name) > maxTagLength:
+			errs.add(field+".name", "must be at most 64 characters long")
+		}
+		if utf8.RuneCountInString(tag.Kind) > maxTagKindLength {
+			errs.add(field+".kind", "must be at most 32 characters long")
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// normalizeTracks checks the track listing and numbers the tracks sent
+// without numbers: the disc defaults to 1 and tracks follow the previous
+// track of the same disc.
+func normalizeTracks(tracks []storage.Track) error {
+	var errs ValidationErrors
+	songs := make(map[int]bool)
+	positions := make(map[[2]int]bool)
+	last := make(map[int]int)
+	for i, track := range tracks {
+		field := "tracks[" + strconv.Itoa(i) + "]"
+		if track.SongID <= 0 {
+			errs.add(field+".songId", "is required")
+		} else if songs[track.SongID] {
+			errs.add(field+".songId", "song is already on the album")
+		}
+		songs[track.SongID] = true
+
+		if track.DiscNumber < 0 || track.TrackNumber < 0 {
+			errs.add(field, "disc and track numbers must be positive")
+			continue
+		}
+		if track.DiscNumber == 0 {
+			track.DiscNumber = 1
+		}
+		if track.TrackNumber == 0 {
+			track.TrackNumber = last[track.DiscNumber] + 1
+		}
+		last[track.DiscNumber] = track.TrackNumber
+
+		position := [2]int{track.DiscNumber, track.TrackNumber}
+		if positions[position] {
+			errs.add(field+".trackNumber", "track number is already taken on the disc")
+		}
+		positions[position] = true
+		tracks[i] = track
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// referenceError reports a reference to a missing or wrong artist or song
+// as a validation error, other errors are returned as is.
+func referenceError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrUnknownArtist):
+		return ValidationErrors{{Field: "artistId", Message: "artist does not exist"}}
+	case errors.Is(err, storage.ErrArtistMismatch):
+		return ValidationErrors{{Field: "artistId", Message: "group names another artist, omit group or artistId"}}
+	case errors.Is(err, storage.ErrUnknownSong):
+		return ValidationErrors{{Field: "tracks", Message: err.Error()}}
+	}
+	return err
+}
+
+// playlistError reports an unknown song or a position out of range of a
+// playlist edit as a validation error.
+func playlistError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrUnknownSong):
+		return ValidationErrors{{Field: "songId", Message: "song does not exist"}}
+	case errors.Is(err, storage.ErrInvalidPosition):
+		return ValidationErrors{{Field: "position", Message: err.Error()}}
+	}
+	return err
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+func TestValidateSong(t *testing.T) {
+	tests := []struct {
+		song storage.Song
+		want []string
+	}{
+		{storage.Song{Group: "Muse", Song: "Hole", ReleaseDate: "16.07.2006", Link: "https://example.com"}, nil},
+		{storage.Song{Group: " ", Song: strings.Repeat("я", 256)}, []string{"group", "song"}},
+		{storage.Song{Group: "Muse", Song: "Hole", Link: "example.com"}, []string{"link"}},
+		{storage.Song{Group: "Muse", Song: "Hole", Link: "ftp://example.com"}, []string{"link"}},
+		{storage.Song{Group: "Muse", Song: "Hole", ReleaseDate: "31.02.2006"}, []string{"releaseDate"}},
+	}
+	for _, tt := range tests {
+		err := validateSong(&tt.song)
+
+		var errs ValidationErrors
+		errors.As(err, &errs)
+		var fields []string
+		for _, e := range errs {
+			fields = append(fields, e.Field)
+		}
+		if !reflect.DeepEqual(fields, tt.want) {
+			t.Errorf("validateSong(%+v) = %v, want errors for %v", tt.song, err, tt.want)
+		}
+	}
+}
+
+func TestNormalizeTracks(t *testing.T) {
+	tracks := []storage.Track{
+		{SongID: 1},
+		{SongID: 2},
+		{SongID: 3, DiscNumber: 2},
+		{SongID: 4, TrackNumber: 5},
+		{SongID: 5},
+	}
+	if err := normalizeTracks(tracks); err != nil {
+		t.Fatal(err)
+	}
+	want := []storage.Track{
+		{SongID: 1, DiscNumber: 1, TrackNumber: 1},
+		{SongID: 2, DiscNumber: 1, TrackNumber: 2},
+		{SongID: 3, DiscNumber: 2, TrackNumber: 1},
+		{SongID: 4, DiscNumber: 1, TrackNumber: 5},
+		{SongID: 5, DiscNumber: 1, TrackNumber: 6},
+	}
+	if !reflect.DeepEqual(tracks, want) {
+		t.Errorf("normalizeTracks = %+v, want %+v", tracks, want)
+	}
+
+	tests := []struct {
+		tracks []storage.Track
+		want   []string
+	}{
+		{[]storage.Track{{}}, []string{"tracks[0].songId"}},
+		{[]storage.Track{{SongID: 1}, {SongID: 1}}, []string{"tracks[1].songId"}},
+		{[]storage.Track{{SongID: 1, TrackNumber: -1}}, []string{"tracks[0]"}},
+		{[]storage.Track{{SongID: 1, TrackNumber: 2}, {SongID: 2, TrackNumber: 2}}, []string{"tracks[1].trackNumber"}},
+	}
+	for _, tt := range tests {
+		err := normalizeTracks(tt.tracks)
+
+		var errs ValidationErrors
+		errors.As(err, &errs)
+		var fields []string
+		for _, e := range errs {
+			fields = append(fields, e.Field)
+		}
+		if !reflect.DeepEqual(fields, tt.want) {
+			t.Errorf("normalizeTracks(%+v) = %v, want errors for %v", tt.tracks, err, tt.want)
+		}
+	}
+}
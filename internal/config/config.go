@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -10,12 +11,19 @@ import (
 type Config struct {
 	ServHost   string
 	ServPort   string
+	DBDriver   string
 	DBHost     string
 	DBPort     string
 	DBUser     string
 	DBPassword string
 	DBName     string
+	DBPath     string
 	MIURL      string
+
+	RequestTimeout time.Duration
+	IdempotencyTTL time.Duration
+	MigrateOnStart bool
+	UniqueSongs    bool
 }
 
 func LoadConfig() *Config {
@@ -27,15 +35,45 @@ func LoadConfig() *Config {
 	return &Config{
 		ServHost:   os.Getenv("SERV_HOST"),
 		ServPort:   os.Getenv("SERV_PORT"),
+		DBDriver:   getEnv("DB_DRIVER", "postgres"),
 		DBHost:     os.Getenv("DB_HOST"),
 		DBPort:     os.Getenv("DB_PORT"),
 		DBUser:     os.Getenv("DB_USER"),
 		DBPassword: os.Getenv("DB_PASSWORD"),
 		DBName:     os.Getenv("DB_NAME"),
+		DBPath:     getEnv("DB_PATH", "songlib.db"),
 		MIURL:      os.Getenv("MI_URL"),
+
+		RequestTimeout: getDuration("REQUEST_TIMEOUT", 10*time.Second),
+		IdempotencyTTL: getDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		MigrateOnStart: getEnv("MIGRATE_ON_START", "true") == "true",
+		UniqueSongs:    getEnv("UNIQUE_SONGS", "false") == "true",
 	}
 }
 
 func (c *Config) DBConnectionString() string {
+	if c.DBDriver == "sqlite" {
+		return "file:" + c.DBPath + "?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)"
+	}
 	return "host=" + c.DBHost + " port=" + c.DBPort + " user=" + c.DBUser + " password=" + c.DBPassword + " dbname=" + c.DBName + " sslmode=disable"
 }
+
+func getEnv(key, def string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return def
+}
+
+func getDuration(key string, def time.Duration) time.Duration {
+	value := getEnv(key, "")
+	if value == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", key, err)
+	}
+	return d
+}
@@ -0,0 +1,309 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+// CreateAlbum godoc
+// @Summary Добавление альбома
+// @Description Добавление нового альбома исполнителя, дата выхода может быть неполной (2006, 2006-07)
+// @Tags albums
+// @Accept  json
+// @Produce  json
+// @Param album body storage.Album true "Album to add"
+// @Success 201 {object} storage.Album
+// @Failure 400 {object} Problem "Invalid JSON"
+// @Failure 422 {object} Problem "Validation failed"
+// @Failure 500 {object} Problem "Failed to create album"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /albums [post]
+func (s *Server) CreateAlbum() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var album storage.Album
+		if err := json.NewDecoder(r.Body).Decode(&album); err != nil {
+			log.Printf("Error decoding JSON: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidJSON, "Invalid JSON")
+			return
+		}
+
+		err := s.app.CreateAlbum(r.Context(), &album)
+		if handleWriteError(w, r, err, "Album") {
+			return
+		} else if err != nil {
+			log.Printf("Error creating album: %v", err)
+			writeInternalError(w, r, err, "Failed to create album")
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(album)
+	}
+}
+
+// GetAlbum godoc
+// @Summary Получение альбома по ID
+// @Description Получение альбома вместе с песнями, упорядоченными по номеру диска и трека
+// @Tags albums
+// @Produce  json
+// @Param id path int true "Album ID"
+// @Success 200 {object} storage.Album
+// @Failure 400 {object} Problem "Invalid ID"
+// @Failure 404 {object} Problem "Album not found"
+// @Failure 500 {object} Problem "Failed to get album"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /albums/{id} [get]
+func (s *Server) GetAlbum() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Printf("Error converting id to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
+			return
+		}
+
+		album, err := s.app.GetAlbum(r.Context(), id)
+		if errors.Is(err, storage.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Album not found")
+			return
+		} else if err != nil {
+			log.Printf("Error getting album: %v", err)
+			writeInternalError(w, r, err, "Failed to get album")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(album)
+	}
+}
+
+// UpdateAlbum godoc
+// @Summary Обновление альбома
+// @Description Обновляет название, исполнителя и дату выхода альбома, список треков не меняется
+// @Tags albums
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Album ID"
+// @Param album body storage.Album true "Updated album"
+// @Success 200 {object} storage.Album
+// @Failure 400 {object} Problem "Invalid ID or JSON"
+// @Failure 404 {object} Problem "Album not found"
+// @Failure 422 {object} Problem "Validation failed"
+// @Failure 500 {object} Problem "Failed to update album"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /albums/{id} [put]
+func (s *Server) UpdateAlbum() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Printf("Error converting id to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
+			return
+		}
+
+		var album storage.Album
+		if err := json.NewDecoder(r.Body).Decode(&album); err != nil {
+			log.Printf("Error decoding JSON: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidJSON, "Invalid JSON")
+			return
+		}
+
+		album.ID = id
+		album.Tracks = nil
+		err = s.app.UpdateAlbum(r.Context(), &album)
+		if handleWriteError(w, r, err, "Album") {
+			return
+		} else if err != nil {
+			log.Printf("Error updating album: %v", err)
+			writeInternalError(w, r, err, "Failed to update album")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(album)
+	}
+}
+
+// DeleteAlbum godoc
+// @Summary Удаление альбома
+// @Description Удаляет альбом по ID, его песни остаются в библиотеке без альбома
+// @Tags albums
+// @Param id path int true "Album ID"
+// @Success 204
+// @Failure 400 {object} Problem "Invalid ID"
+// @Failure 404 {object} Problem "Album not found"
+// @Failure 500 {object} Problem "Failed to delete album"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /albums/{id} [delete]
+func (s *Server) DeleteAlbum() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Printf("Error converting id to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
+			return
+		}
+
+		err = s.app.DeleteAlbum(r.Context(), id)
+		if errors.Is(err, storage.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Album not found")
+			return
+		} else if err != nil {
+			log.Printf("Error deleting album: %v", err)
+			writeInternalError(w, r, err, "Failed to delete album")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GetAlbums godoc
+// @Summary Получение списка альбомов
+// @Description Получение списка альбомов без песен: artistId - альбомы исполнителя, title - часть названия,
+// @Description limit - количество выводимых данных, offset - с какого элемента
+// @Tags albums
+// @Produce  json
+// @Param artistId query int false "Filter by artist ID"
+// @Param title query string false "Filter by title substring"
+// @Param limit query int false "Limit the number of results, at most 100"
+// @Param offset query int false "Offset for pagination"
+// @Success 200 {object} storage.AlbumPage
+// @Header 200 {integer} X-Total-Count "Total number of matching albums"
+// @Header 200 {string} Link "Link to the next page"
+// @Failure 400 {object} Problem "Invalid artistId or offset"
+// @Failure 500 {object} Problem "Failed to get albums"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /albums [get]
+func (s *Server) GetAlbums() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		artistID, err := positiveParam(r.URL.Query(), "artistId", 0)
+		if err != nil {
+			log.Printf("Error parsing artistId: %v", err)
+			writeParamError(w, r, "artistId", err)
+			return
+		}
+
+		limit := parseLimit(r.URL.Query())
+		offset, err := parseOffset(r.URL.Query())
+		if err != nil {
+			log.Printf("Error parsing offset: %v", err)
+			writeParamError(w, r, "offset", err)
+			return
+		}
+
+		page, err := s.app.GetAlbums(r.Context(), storage.AlbumQuery{
+			ArtistID: artistID,
+			Title:    r.URL.Query().Get("title"),
+			Limit:    limit,
+			Offset:   offset,
+		})
+		if err != nil {
+			log.Printf("Error getting albums: %v", err)
+			writeInternalError(w, r, err, "Failed to get albums")
+			return
+		}
+
+		page.Next = nextPageLink(r.URL, page.Offset, page.Limit, page.Total, nil)
+		if page.Next != "" {
+			w.Header().Set("Link", "<"+page.Next+`>; rel="next"`)
+		}
+		w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(page)
+	}
+}
+
+// SetAlbumTracks godoc
+// @Summary Изменение списка треков альбома
+// @Description Заменяет список треков альбома. Песня может быть только на одном альбоме и переносится с прежнего.
+// @Description Без discNumber используется первый диск, без trackNumber трек получает следующий номер на диске.
+// @Description Песни без даты выхода получают дату альбома
+// @Tags albums
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Album ID"
+// @Param tracks body []storage.Track true "Track listing"
+// @Success 200 {object} storage.Album
+// @Failure 400 {object} Problem "Invalid ID or JSON"
+// @Failure 404 {object} Problem "Album not found"
+// @Failure 422 {object} Problem "Invalid track listing or unknown song"
+// @Failure 500 {object} Problem "Failed to set album tracks"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /albums/{id}/tracks [put]
+func (s *Server) SetAlbumTracks() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Printf("Error converting id to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
+			return
+		}
+
+		var tracks []storage.Track
+		if err := json.NewDecoder(r.Body).Decode(&tracks); err != nil {
+			log.Printf("Error decoding JSON: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidJSON, "Invalid JSON")
+			return
+		}
+
+		album, err := s.app.SetTracks(r.Context(), id, tracks)
+		if handleWriteError(w, r, err, "Album") {
+			return
+		} else if err != nil {
+			log.Printf("Error setting album tracks: %v", err)
+			writeInternalError(w, r, err, "Failed to set album tracks")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(album)
+	}
+}
+
+// RemoveAlbumTrack godoc
+// @Summary Удаление трека из альбома
+// @Description Убирает песню из альбома, сама песня остается в библиотеке
+// @Tags albums
+// @Param id path int true "Album ID"
+// @Param songId path int true "Song ID"
+// @Success 204
+// @Failure 400 {object} Problem "Invalid ID"
+// @Failure 404 {object} Problem "Track not found"
+// @Failure 500 {object} Problem "Failed to remove track"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /albums/{id}/tracks/{songId} [delete]
+func (s *Server) RemoveAlbumTrack() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Printf("Error converting id to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
+			return
+		}
+		songID, err := strconv.Atoi(r.PathValue("songId"))
+		if err != nil {
+			log.Printf("Error converting songId to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid song ID")
+			return
+		}
+
+		err = s.app.RemoveTrack(r.Context(), id, songID)
+		if errors.Is(err, storage.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Track not found")
+			return
+		} else if err != nil {
+			log.Printf("Error removing album track: %v", err)
+			writeInternalError(w, r, err, "Failed to remove track")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
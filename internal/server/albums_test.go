@@ -0,0 +1,60 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+func TestAlbumHandlers(t *testing.T) {
+	h := newTestHandler(t,
+		&storage.Song{Group: "Muse", Song: "Starlight"},
+		&storage.Song{Group: "Muse", Song: "Take a Bow"},
+		&storage.Song{Group: "Kino", Song: "Kukushka"},
+	)
+
+	var album storage.Album
+	w := serve(t, h, newRequest(http.MethodPost, "/albums", `{"title": "Black Holes and Revelations", "artistId": 1, "releaseDate": "03.07.2006"}`), &album)
+	if w.Code != http.StatusCreated || album.Artist != "Muse" || album.ReleaseDate != "2006-07-03" {
+		t.Fatalf("POST /albums = %d %+v, want the album of Muse", w.Code, album)
+	}
+
+	w = serve(t, h, newRequest(http.MethodPut, "/albums/1/tracks", `[{"songId": 2}, {"songId": 1}]`), &album)
+	if w.Code != http.StatusOK || len(album.Tracks) != 2 || album.Tracks[0].Song != "Take a Bow" || album.Tracks[1].TrackNumber != 2 {
+		t.Errorf("PUT /albums/1/tracks = %d %+v, want 2 numbered tracks", w.Code, album.Tracks)
+	}
+
+	var page storage.AlbumPage
+	if w := serve(t, h, newRequest(http.MethodGet, "/albums?artistId=1&title=holes", ""), &page); w.Code != http.StatusOK || page.Total != 1 || page.Items[0].Tracks != nil {
+		t.Errorf("GET /albums = %d %+v, want 1 album without tracks", w.Code, page)
+	}
+
+	tests := []struct {
+		method, target, body string
+		status               int
+		code                 string
+	}{
+		{http.MethodGet, "/albums/1", "", http.StatusOK, ""},
+		{http.MethodGet, "/albums/9", "", http.StatusNotFound, codeNotFound},
+		{http.MethodGet, "/albums?artistId=x", "", http.StatusBadRequest, codeInvalidParameter},
+		{http.MethodPost, "/albums", `{"title": "Gruppa krovi", "artistId": 9}`, http.StatusUnprocessableEntity, codeValidation},
+		{http.MethodPost, "/albums", `{"title": "", "artistId": 2}`, http.StatusUnprocessableEntity, codeValidation},
+		{http.MethodPut, "/albums/1", `{"title": "Absolution", "artistId": 1}`, http.StatusOK, ""},
+		{http.MethodPut, "/albums/9", `{"title": "Absolution", "artistId": 1}`, http.StatusNotFound, codeNotFound},
+		{http.MethodPut, "/albums/1/tracks", `[{"songId": 9}]`, http.StatusUnprocessableEntity, codeValidation},
+		{http.MethodPut, "/albums/1/tracks", `[{"songId": 1}, {"songId": 1}]`, http.StatusUnprocessableEntity, codeValidation},
+		{http.MethodPut, "/albums/1/tracks", `{}`, http.StatusBadRequest, codeInvalidJSON},
+		{http.MethodDelete, "/albums/1/tracks/3", "", http.StatusNotFound, codeNotFound},
+		{http.MethodDelete, "/albums/1/tracks/2", "", http.StatusNoContent, ""},
+		{http.MethodDelete, "/albums/1", "", http.StatusNoContent, ""},
+		{http.MethodDelete, "/albums/1", "", http.StatusNotFound, codeNotFound},
+	}
+	for _, tt := range tests {
+		var p Problem
+		w := serve(t, h, newRequest(tt.method, tt.target, tt.body), &p)
+		if w.Code != tt.status || (tt.code != "" && p.Code != tt.code) {
+			t.Errorf("%s %s %s = %d %s, want %d %s", tt.method, tt.target, tt.body, w.Code, p.Code, tt.status, tt.code)
+		}
+	}
+}
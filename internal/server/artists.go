@@ -0,0 +1,262 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+// CreateArtist godoc
+// @Summary Добавление исполнителя
+// @Description Добавление нового исполнителя. Имена, отличающиеся только регистром и пробелами, считаются одинаковыми
+// @Tags artists
+// @Accept  json
+// @Produce  json
+// @Param artist body storage.Artist true "Artist to add"
+// @Success 201 {object} storage.Artist
+// @Failure 400 {object} Problem "Invalid JSON"
+// @Failure 409 {object} Problem "Artist already exists"
+// @Failure 422 {object} Problem "Validation failed"
+// @Failure 500 {object} Problem "Failed to create artist"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /artists [post]
+func (s *Server) CreateArtist() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var artist storage.Artist
+		if err := json.NewDecoder(r.Body).Decode(&artist); err != nil {
+			log.Printf("Error decoding JSON: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidJSON, "Invalid JSON")
+			return
+		}
+
+		err := s.app.CreateArtist(r.Context(), &artist)
+		if handleWriteError(w, r, err, "Artist") {
+			return
+		} else if err != nil {
+			log.Printf("Error creating artist: %v", err)
+			writeInternalError(w, r, err, "Failed to create artist")
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(artist)
+	}
+}
+
+// GetArtist godoc
+// @Summary Получение исполнителя по ID
+// @Description Получение одного исполнителя по ID
+// @Tags artists
+// @Produce  json
+// @Param id path int true "Artist ID"
+// @Success 200 {object} storage.Artist
+// @Failure 400 {object} Problem "Invalid ID"
+// @Failure 404 {object} Problem "Artist not found"
+// @Failure 500 {object} Problem "Failed to get artist"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /artists/{id} [get]
+func (s *Server) GetArtist() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Printf("Error converting id to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
+			return
+		}
+
+		artist, err := s.app.GetArtist(r.Context(), id)
+		if errors.Is(err, storage.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Artist not found")
+			return
+		} else if err != nil {
+			log.Printf("Error getting artist: %v", err)
+			writeInternalError(w, r, err, "Failed to get artist")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(artist)
+	}
+}
+
+// UpdateArtist godoc
+// @Summary Обновление исполнителя
+// @Description Обновляет данные исполнителя, при переименовании поле group его песен тоже меняется
+// @Tags artists
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Artist ID"
+// @Param artist body storage.Artist true "Updated artist"
+// @Success 200 {object} storage.Artist
+// @Failure 400 {object} Problem "Invalid ID or JSON"
+// @Failure 404 {object} Problem "Artist not found"
+// @Failure 409 {object} Problem "Artist already exists or renamed songs duplicate other songs"
+// @Failure 422 {object} Problem "Validation failed"
+// @Failure 500 {object} Problem "Failed to update artist"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /artists/{id} [put]
+func (s *Server) UpdateArtist() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Printf("Error converting id to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
+			return
+		}
+
+		var artist storage.Artist
+		if err := json.NewDecoder(r.Body).Decode(&artist); err != nil {
+			log.Printf("Error decoding JSON: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidJSON, "Invalid JSON")
+			return
+		}
+
+		artist.ID = id
+		err = s.app.UpdateArtist(r.Context(), &artist)
+		if handleWriteError(w, r, err, "Artist") {
+			return
+		} else if err != nil {
+			log.Printf("Error updating artist: %v", err)
+			writeInternalError(w, r, err, "Failed to update artist")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(artist)
+	}
+}
+
+// DeleteArtist godoc
+// @Summary Удаление исполнителя
+// @Description Удаляет исполнителя по ID, исполнителя с песнями или альбомами удалить нельзя
+// @Tags artists
+// @Param id path int true "Artist ID"
+// @Success 204
+// @Failure 400 {object} Problem "Invalid ID"
+// @Failure 404 {object} Problem "Artist not found"
+// @Failure 409 {object} Problem "Artist has songs or albums"
+// @Failure 500 {object} Problem "Failed to delete artist"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /artists/{id} [delete]
+func (s *Server) DeleteArtist() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Printf("Error converting id to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
+			return
+		}
+
+		err = s.app.DeleteArtist(r.Context(), id)
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Artist not found")
+			return
+		case errors.Is(err, storage.ErrConflict):
+			writeError(w, r, http.StatusConflict, codeConflict, "Artist has songs or albums")
+			return
+		case err != nil:
+			log.Printf("Error deleting artist: %v", err)
+			writeInternalError(w, r, err, "Failed to delete artist")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GetArtists godoc
+// @Summary Получение списка исполнителей
+// @Description Получение списка исполнителей по алфавиту: name - часть имени, limit - количество выводимых данных, offset - с какого элемента
+// @Tags artists
+// @Produce  json
+// @Param name query string false "Filter by name substring"
+// @Param limit query int false "Limit the number of results, at most 100"
+// @Param offset query int false "Offset for pagination"
+// @Success 200 {object} storage.ArtistPage
+// @Header 200 {integer} X-Total-Count "Total number of matching artists"
+// @Header 200 {string} Link "Link to the next page"
+// @Failure 400 {object} Problem "Invalid offset"
+// @Failure 500 {object} Problem "Failed to get artists"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /artists [get]
+func (s *Server) GetArtists() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := parseLimit(r.URL.Query())
+		offset, err := parseOffset(r.URL.Query())
+		if err != nil {
+			log.Printf("Error parsing offset: %v", err)
+			writeParamError(w, r, "offset", err)
+			return
+		}
+
+		page, err := s.app.GetArtists(r.Context(), storage.ArtistQuery{
+			Name:   r.URL.Query().Get("name"),
+			Limit:  limit,
+			Offset: offset,
+		})
+		if err != nil {
+			log.Printf("Error getting artists: %v", err)
+			writeInternalError(w, r, err, "Failed to get artists")
+			return
+		}
+
+		page.Next = nextPageLink(r.URL, page.Offset, page.Limit, page.Total, nil)
+		if page.Next != "" {
+			w.Header().Set("Link", "<"+page.Next+`>; rel="next"`)
+		}
+		w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(page)
+	}
+}
+
+// GetArtistSongs godoc
+// @Summary Получение песен исполнителя
+// @Description Получение списка песен исполнителя, параметры фильтрации, сортировки и пагинации те же, что у GET /songs
+// @Tags artists
+// @Produce  json
+// @Param id path int true "Artist ID"
+// @Param limit query int false "Limit the number of results, at most 100"
+// @Param offset query int false "Offset for pagination"
+// @Param cursor query string false "Cursor of the next page"
+// @Param sort query string false "Sort fields, e.g. -releaseDate,song"
+// @Success 200 {object} storage.SongPage
+// @Header 200 {integer} X-Total-Count "Total number of matching songs"
+// @Header 200 {string} Link "Link to the next page"
+// @Header 200 {string} X-Next-Cursor "Cursor of the next page"
+// @Failure 400 {object} Problem "Invalid ID, unknown filter or sort field, invalid cursor"
+// @Failure 404 {object} Problem "Artist not found"
+// @Failure 500 {object} Problem "Failed to get songs"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /artists/{id}/songs [get]
+func (s *Server) GetArtistSongs() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Printf("Error converting id to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
+			return
+		}
+
+		_, err = s.app.GetArtist(r.Context(), id)
+		if errors.Is(err, storage.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Artist not found")
+			return
+		} else if err != nil {
+			log.Printf("Error getting artist: %v", err)
+			writeInternalError(w, r, err, "Failed to get songs")
+			return
+		}
+
+		s.listSongs(w, r, func(ctx context.Context, q storage.ListQuery) (*storage.SongPage, error) {
+			return s.app.GetArtistSongs(ctx, id, q)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+func TestArtistHandlers(t *testing.T) {
+	h := newTestHandler(t,
+		&storage.Song{Group: "Muse", Song: "Hole"},
+		&storage.Song{Group: "Kino", Song: "Kukushka"},
+		&storage.Song{Group: "Muse", Song: "Uprising"},
+	)
+
+	var artist storage.Artist
+	if w := serve(t, h, newRequest(http.MethodPost, "/artists", `{"name": " Queen ", "description": "British rock band"}`), &artist); w.Code != http.StatusCreated || artist.Name != "Queen" {
+		t.Errorf("POST /artists = %d %+v, want Queen created", w.Code, artist)
+	}
+
+	var page storage.ArtistPage
+	w := serve(t, h, newRequest(http.MethodGet, "/artists?name=u&limit=1", ""), &page)
+	if w.Code != http.StatusOK || page.Total != 2 || len(page.Items) != 1 || page.Items[0].Name != "Muse" {
+		t.Fatalf("GET /artists = %d %+v, want the first of Muse and Queen", w.Code, page)
+	}
+	if want := "/artists?limit=1&name=u&offset=1"; page.Next != want {
+		t.Errorf("next = %q, want %q", page.Next, want)
+	}
+
+	var songs storage.SongPage
+	if w := serve(t, h, newRequest(http.MethodGet, "/artists/1/songs?sort=-song", ""), &songs); w.Code != http.StatusOK || len(songs.Items) != 2 || songs.Items[0].Song != "Uprising" {
+		t.Errorf("GET /artists/1/songs = %d %+v, want 2 songs of Muse", w.Code, songs)
+	}
+
+	tests := []struct {
+		method, target, body string
+		status               int
+		code                 string
+	}{
+		{http.MethodGet, "/artists/1", "", http.StatusOK, ""},
+		{http.MethodGet, "/artists/9", "", http.StatusNotFound, codeNotFound},
+		{http.MethodGet, "/artists/x", "", http.StatusBadRequest, codeInvalidID},
+		{http.MethodGet, "/artists/9/songs", "", http.StatusNotFound, codeNotFound},
+		{http.MethodPost, "/artists", `{"name": "muse"}`, http.StatusConflict, codeConflict},
+		{http.MethodPost, "/artists", `{"name": " "}`, http.StatusUnprocessableEntity, codeValidation},
+		{http.MethodPut, "/artists/2", `{"name": "Kino"}`, http.StatusOK, ""},
+		{http.MethodPut, "/artists/2", `{"name": "Muse"}`, http.StatusConflict, codeConflict},
+		{http.MethodPut, "/artists/9", `{"name": "Nobody"}`, http.StatusNotFound, codeNotFound},
+		{http.MethodDelete, "/artists/1", "", http.StatusConflict, codeConflict},
+		{http.MethodDelete, "/artists/3", "", http.StatusNoContent, ""},
+		{http.MethodPost, "/songs", `{"artistId": 9, "song": "Hole"}`, http.StatusUnprocessableEntity, codeValidation},
+		{http.MethodPost, "/songs", `{"artistId": 1, "group": "Kino", "song": "Hole"}`, http.StatusUnprocessableEntity, codeValidation},
+	}
+	for _, tt := range tests {
+		var p Problem
+		w := serve(t, h, newRequest(tt.method, tt.target, tt.body), &p)
+		if w.Code != tt.status || (tt.code != "" && p.Code != tt.code) {
+			t.Errorf("%s %s %s = %d %s, want %d %s", tt.method, tt.target, tt.body, w.Code, p.Code, tt.status, tt.code)
+		}
+	}
+}
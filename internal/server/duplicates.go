@@ -0,0 +1,144 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+// GetDuplicates godoc
+// @Summary Поиск дубликатов песен
+// @Description Группы песен с одинаковыми исполнителем и названием без учета регистра, знаков препинания и алфавита
+// @Description ("Кино - Группа крови" и "kino gruppa krovi"), тексты которых похожи.
+// @Description Первой в группе идет самая старая песня, в нее предлагается объединить остальные (POST /songs/{id}/merge).
+// @Description Дубликаты нужно объединить перед тем, как включить UNIQUE_SONGS, после этого новые не появляются.
+// @Description score - сходство текста с первой песней от 0 до 1, песни без текста получают 1.
+// @Description minScore - минимальное сходство текстов, по умолчанию 0.5
+// @Tags songs
+// @Produce  json
+// @Param minScore query number false "Minimum lyrics similarity, from 0 to 1" default(0.5)
+// @Param limit query int false "Limit the number of groups, at most 100"
+// @Param offset query int false "Offset for pagination"
+// @Success 200 {object} storage.DuplicatePage
+// @Header 200 {integer} X-Total-Count "Total number of duplicate groups"
+// @Header 200 {string} Link "Link to the next page"
+// @Failure 400 {object} Problem "Invalid minScore or offset"
+// @Failure 500 {object} Problem "Failed to find duplicates"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /songs/duplicates [get]
+func (s *Server) GetDuplicates() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		minScore := storage.DefaultDuplicateScore
+		if v := r.URL.Query().Get("minScore"); v != "" {
+			var err error
+			minScore, err = strconv.ParseFloat(v, 64)
+			if err != nil || minScore < 0 || minScore > 1 {
+				writeParamError(w, r, "minScore", errors.New("must be a number from 0 to 1"))
+				return
+			}
+		}
+
+		limit := parseLimit(r.URL.Query())
+		offset, err := parseOffset(r.URL.Query())
+		if err != nil {
+			log.Printf("Error parsing offset: %v", err)
+			writeParamError(w, r, "offset", err)
+			return
+		}
+
+		page, err := s.app.Duplicates(r.Context(), storage.DuplicateQuery{
+			MinScore: minScore,
+			Limit:    limit,
+			Offset:   offset,
+		})
+		if err != nil {
+			log.Printf("Error finding duplicates: %v", err)
+			writeInternalError(w, r, err, "Failed to find duplicates")
+			return
+		}
+
+		page.Next = nextPageLink(r.URL, page.Offset, page.Limit, page.Total, nil)
+		if page.Next != "" {
+			w.Header().Set("Link", "<"+page.Next+`>; rel="next"`)
+		}
+		w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(page)
+	}
+}
+
+// MergeSong godoc
+// @Summary Объединение дубликатов с песней
+// @Description Переносит в песню записи плейлистов и теги дубликатов, заполняет пустые поля песни (дата, текст, ссылка, альбом)
+// @Description из дубликатов и удаляет их. Запросы GET к ID дубликата перенаправляются на песню (301).
+// @Description Дубликаты с другим исполнителем или названием объединяются только с force
+// @Tags songs
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Song ID"
+// @Param merge body storage.SongMerge true "IDs of the duplicates"
+// @Success 200 {object} storage.Song
+// @Failure 400 {object} Problem "Invalid ID or JSON"
+// @Failure 404 {object} Problem "Song not found"
+// @Failure 409 {object} Problem "Duplicate has another band or title, or its album track is taken"
+// @Failure 422 {object} Problem "Unknown duplicate"
+// @Failure 500 {object} Problem "Failed to merge songs"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /songs/{id}/merge [post]
+func (s *Server) MergeSong() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Printf("Error converting id to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
+			return
+		}
+
+		var merge storage.SongMerge
+		if err := json.NewDecoder(r.Body).Decode(&merge); err != nil {
+			log.Printf("Error decoding JSON: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidJSON, "Invalid JSON")
+			return
+		}
+
+		song, err := s.app.MergeSongs(r.Context(), id, merge)
+		if errors.Is(err, storage.ErrNotDuplicate) {
+			log.Printf("Error merging songs: %v", err)
+			writeError(w, r, http.StatusConflict, codeNotDuplicate, "Duplicate has another band or title, set force to merge it")
+			return
+		} else if handleWriteError(w, r, err, "Song") {
+			return
+		} else if err != nil {
+			log.Printf("Error merging songs: %v", err)
+			writeInternalError(w, r, err, "Failed to merge songs")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(song)
+	}
+}
+
+// redirectSong answers a request for a missing song: the id of a merged
+// duplicate is redirected to the same path of its song. It reports
+// whether it wrote the response.
+func (s *Server) redirectSong(w http.ResponseWriter, r *http.Request, id int, path string) bool {
+	songID, err := s.app.SongRedirect(r.Context(), id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return false
+	} else if err != nil {
+		log.Printf("Error getting song redirect: %v", err)
+		writeInternalError(w, r, err, "Failed to get song")
+		return true
+	}
+
+	u := url.URL{Path: "/songs/" + strconv.Itoa(songID) + path, RawQuery: r.URL.RawQuery}
+	http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+	return true
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+func TestGetDuplicates(t *testing.T) {
+	h := newTestHandler(t,
+		&storage.Song{Group: "Кино", Song: "Группа крови"},
+		&storage.Song{Group: "kino", Song: "gruppa krovi"},
+		&storage.Song{Group: "Muse", Song: "Hole"},
+		&storage.Song{Group: "MUSE", Song: "hole"},
+	)
+
+	var page storage.DuplicatePage
+	w := serve(t, h, newRequest(http.MethodGet, "/songs/duplicates?limit=1", ""), &page)
+	if w.Code != http.StatusOK || page.Total != 2 || len(page.Items) != 1 || len(page.Items[0].Songs) != 2 {
+		t.Fatalf("GET /songs/duplicates = %d %+v, want the first of 2 groups", w.Code, page)
+	}
+	if want := "/songs/duplicates?limit=1&offset=1"; page.Next != want || w.Header().Get("X-Total-Count") != "2" {
+		t.Errorf("next = %q, X-Total-Count %q, want %q and 2", page.Next, w.Header().Get("X-Total-Count"), want)
+	}
+
+	for _, target := range []string{"/songs/duplicates?minScore=2", "/songs/duplicates?minScore=x"} {
+		var p Problem
+		if w := serve(t, h, newRequest(http.MethodGet, target, ""), &p); w.Code != http.StatusBadRequest || p.Code != codeInvalidParameter {
+			t.Errorf("GET %s = %d %s, want %d %s", target, w.Code, p.Code, http.StatusBadRequest, codeInvalidParameter)
+		}
+	}
+}
+
+func TestMergeSong(t *testing.T) {
+	h := newTestHandler(t,
+		&storage.Song{Group: "Кино", Song: "Группа крови"},
+		&storage.Song{Group: "kino", Song: "gruppa krovi (live)", Text: "Teploe mesto"},
+		&storage.Song{Group: "Muse", Song: "Hole"},
+	)
+
+	tests := []struct {
+		target, body string
+		status       int
+		code         string
+	}{
+		{"/songs/x/merge", `{"duplicates": [2]}`, http.StatusBadRequest, codeInvalidID},
+		{"/songs/1/merge", `{`, http.StatusBadRequest, codeInvalidJSON},
+		{"/songs/1/merge", `{"duplicates": []}`, http.StatusUnprocessableEntity, codeValidation},
+		{"/songs/1/merge", `{"duplicates": [1]}`, http.StatusUnprocessableEntity, codeValidation},
+		{"/songs/1/merge", `{"duplicates": [9]}`, http.StatusUnprocessableEntity, codeValidation},
+		{"/songs/9/merge", `{"duplicates": [2]}`, http.StatusNotFound, codeNotFound},
+		{"/songs/1/merge", `{"duplicates": [2]}`, http.StatusConflict, codeNotDuplicate},
+	}
+	for _, tt := range tests {
+		var p Problem
+		if w := serve(t, h, newRequest(http.MethodPost, tt.target, tt.body), &p); w.Code != tt.status || p.Code != tt.code {
+			t.Errorf("POST %s %s = %d %s, want %d %s", tt.target, tt.body, w.Code, p.Code, tt.status, tt.code)
+		}
+	}
+
+	var song storage.Song
+	w := serve(t, h, newRequest(http.MethodPost, "/songs/1/merge", `{"duplicates": [2, 2], "force": true}`), &song)
+	if w.Code != http.StatusOK || song.ID != 1 || song.Text != "Teploe mesto" {
+		t.Fatalf("POST /songs/1/merge = %d %+v, want the song with the lyrics of the duplicate", w.Code, song)
+	}
+
+	redirects := []struct {
+		target, location string
+	}{
+		{"/songs/2", "/songs/1"},
+		{"/songs/2/text?page=1", "/songs/1/text?page=1"},
+	}
+	for _, tt := range redirects {
+		w := serve(t, h, newRequest(http.MethodGet, tt.target, ""), nil)
+		if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != tt.location {
+			t.Errorf("GET %s = %d to %q, want %d to %q", tt.target, w.Code, w.Header().Get("Location"), http.StatusMovedPermanently, tt.location)
+		}
+	}
+	if w := serve(t, h, newRequest(http.MethodGet, "/songs/9", ""), nil); w.Code != http.StatusNotFound {
+		t.Errorf("GET of a missing song status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/fevse/songlib/internal/app"
+	"github.com/fevse/songlib/internal/storage"
+)
+
+// Machine-readable problem codes, clients may rely on them.
+const (
+	codeInvalidJSON      = "invalid_json"
+	codeInvalidID        = "invalid_id"
+	codeInvalidParameter = "invalid_parameter"
+	codeInvalidFilter    = "invalid_filter"
+	codeValidation       = "validation_failed"
+	codeInvalidPatch     = "invalid_patch"
+	codePatchTestFailed  = "patch_test_failed"
+	codeUnsupportedMedia = "unsupported_media_type"
+	codeNotFound         = "not_found"
+	codeConflict         = "conflict"
+	codeTimeout          = "timeout"
+	codeInternal         = "internal_error"
+
+	// An Idempotency-Key is reused for another request or while the
+	// first request with it is in progress.
+	codeIdempotencyKeyReused  = "idempotency_key_reused"
+	codeIdempotencyInProgress = "idempotency_key_in_progress"
+
+	// A song merged into another one has a different band or title.
+	codeNotDuplicate = "not_duplicate"
+)
+
+// Problem is an RFC 7807 error response.
+type Problem struct {
+	Type      string       `json:"type" example:"urn:songlib:problem:not_found"`
+	Title     string       `json:"title" example:"Not Found"`
+	Status    int          `json:"status" example:"404"`
+	Detail    string       `json:"detail,omitempty" example:"Song not found"`
+	Instance  string       `json:"instance,omitempty" example:"/songs/42"`
+	Code      string       `json:"code" example:"not_found"`
+	RequestID string       `json:"requestId,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func newProblem(status int, code, detail string) *Problem {
+	return &Problem{
+		Type:   "urn:songlib:problem:" + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, p *Problem) {
+	p.Instance = r.URL.Path
+	p.RequestID = requestIDFrom(r.Context())
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		log.Printf("Error encoding problem: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	writeProblem(w, r, newProblem(status, code, detail))
+}
+
+// writeInternalError reports a failed request, telling a request that
+// ran out of time apart from other failures.
+func writeInternalError(w http.ResponseWriter, r *http.Request, err error, detail string) {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(r.Context().Err(), context.DeadlineExceeded) {
+		writeError(w, r, http.StatusGatewayTimeout, codeTimeout, "Request timed out")
+		return
+	}
+	writeError(w, r, http.StatusInternalServerError, codeInternal, detail)
+}
+
+func writeParamError(w http.ResponseWriter, r *http.Request, param string, err error) {
+	p := newProblem(http.StatusBadRequest, codeInvalidParameter, "Invalid "+param)
+	p.Errors = []FieldError{{Field: param, Message: err.Error()}}
+	writeProblem(w, r, p)
+}
+
+func writeFilterError(w http.ResponseWriter, r *http.Request, err error) {
+	var filterErr *storage.FilterError
+	if !errors.As(err, &filterErr) {
+		writeError(w, r, http.StatusBadRequest, codeInvalidFilter, err.Error())
+		return
+	}
+
+	p := newProblem(http.StatusBadRequest, codeInvalidFilter, "Invalid filter or sort")
+	p.Errors = []FieldError{{Field: filterErr.Field, Message: filterErr.Error()}}
+	writeProblem(w, r, p)
+}
+
+// writeQueryError reports an error of parseListQuery.
+func writeQueryError(w http.ResponseWriter, r *http.Request, err error) {
+	var perr *paramError
+	if errors.As(err, &perr) {
+		writeParamError(w, r, perr.param, perr.err)
+		return
+	}
+	writeFilterError(w, r, err)
+}
+
+func writeValidationError(w http.ResponseWriter, r *http.Request, errs app.ValidationErrors, resource string) {
+	p := newProblem(http.StatusUnprocessableEntity, codeValidation, resource+" is invalid")
+	for _, err := range errs {
+		p.Errors = append(p.Errors, FieldError{Field: err.Field, Message: err.Message})
+	}
+	writeProblem(w, r, p)
+}
+
+// handleWriteError writes the response for errors shared by all writes
+// of a resource, "Song" or "Artist", and reports whether it did.
+func handleWriteError(w http.ResponseWriter, r *http.Request, err error, resource string) bool {
+	var verrs app.ValidationErrors
+	switch {
+	case errors.As(err, &verrs):
+		writeValidationError(w, r, verrs, resource)
+	case errors.Is(err, storage.ErrNotFound):
+		writeError(w, r, http.StatusNotFound, codeNotFound, resource+" not found")
+	case errors.Is(err, storage.ErrConflict):
+		writeError(w, r, http.StatusConflict, codeConflict, resource+" already exists")
+	default:
+		return false
+	}
+	return true
+}
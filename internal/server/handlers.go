@@ -1,41 +1,115 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"log"
+	"mime"
 	"net/http"
+	"net/url"
 	"strconv"
 
+	"github.com/fevse/songlib/internal/app"
 	"github.com/fevse/songlib/internal/storage"
 )
 
 // CreateSong godoc
 // @Summary Добавление новой песни
-// @Description Добавление новой песни с получением дополнительных данных от стороннего API
+// @Description Добавление новой песни с получением дополнительных данных от стороннего API.
+// @Description Такая же песня - с теми же исполнителем и названием без учета регистра, знаков препинания и алфавита.
+// @Description onConflict - что делать, если такая песня уже есть: error - ошибка 409, ignore - вернуть существующую песню,
+// @Description update - перезаписать существующую песню новыми данными. С ignore и update запрос можно безопасно повторять,
+// @Description код 201 означает, что песня создана, 200 - что вернулась существующая.
+// @Description Без onConflict песня создается, даже если такая уже есть, кроме случая UNIQUE_SONGS=true - тогда ошибка 409.
+// @Description Idempotency-Key - ключ запроса от клиента: повтор запроса с тем же ключом возвращает сохраненный ответ
+// @Description (с заголовком Idempotent-Replayed), тот же ключ с другим запросом - ошибка 422. Ответ хранится IDEMPOTENCY_TTL
 // @Tags songs
 // @Accept  json
 // @Produce  json
 // @Param song body storage.Song true "Song to add"
+// @Param onConflict query string false "What to do when the song exists" Enums(error, ignore, update)
+// @Param Idempotency-Key header string false "Unique key of the request, retries with it are safe"
 // @Success 201 {object} storage.Song
-// @Failure 400 {string} string "Invalid JSON"
-// @Failure 500 {string} string "Failed to create song"
+// @Success 200 {object} storage.Song "Existing song, ignored or updated"
+// @Failure 400 {object} Problem "Invalid JSON or onConflict"
+// @Failure 409 {object} Problem "Song already exists or request with the Idempotency-Key is in progress"
+// @Failure 422 {object} Problem "Validation failed or Idempotency-Key used for another request"
+// @Failure 500 {object} Problem "Failed to create song"
+// @Failure 504 {object} Problem "Request timed out"
 // @Router /songs [post]
 func (s *Server) CreateSong() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		onConflict := app.OnConflict(r.URL.Query().Get("onConflict"))
+		switch onConflict {
+		case "", app.OnConflictError, app.OnConflictIgnore, app.OnConflictUpdate:
+		default:
+			writeParamError(w, r, "onConflict", errors.New("must be error, ignore or update"))
+			return
+		}
+
 		var song storage.Song
 		if err := json.NewDecoder(r.Body).Decode(&song); err != nil {
 			log.Printf("Error decoding JSON: %v", err)
-			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, codeInvalidJSON, "Invalid JSON")
 			return
 		}
 
-		if err := s.app.CreateSong(&song); err != nil {
+		created, err := s.app.CreateSong(r.Context(), &song, onConflict)
+		if handleWriteError(w, r, err, "Song") {
+			return
+		} else if err != nil {
 			log.Printf("Error creating song: %v", err)
-			http.Error(w, "Failed to create song", http.StatusInternalServerError)
+			writeInternalError(w, r, err, "Failed to create song")
 			return
 		}
 
-		w.WriteHeader(http.StatusCreated)
+		if created {
+			w.WriteHeader(http.StatusCreated)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		json.NewEncoder(w).Encode(song)
+	}
+}
+
+// GetSong godoc
+// @Summary Получение песни по ID
+// @Description Получение одной песни из библиотеки по ID. ID песни, объединенной с другой, перенаправляется на нее
+// @Tags songs
+// @Produce  json
+// @Param id path int true "Song ID"
+// @Success 200 {object} storage.Song
+// @Success 301 "Song was merged into another one, Location points to it"
+// @Failure 400 {object} Problem "Invalid ID"
+// @Failure 404 {object} Problem "Song not found"
+// @Failure 500 {object} Problem "Failed to get song"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /songs/{id} [get]
+func (s *Server) GetSong() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Printf("Error converting id to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
+			return
+		}
+
+		song, err := s.app.GetSong(r.Context(), id)
+		if errors.Is(err, storage.ErrNotFound) {
+			if s.redirectSong(w, r, id, "") {
+				return
+			}
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Song not found")
+			return
+		} else if err != nil {
+			log.Printf("Error getting song: %v", err)
+			writeInternalError(w, r, err, "Failed to get song")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(song)
 	}
 }
@@ -48,29 +122,106 @@ func (s *Server) CreateSong() http.HandlerFunc {
 // @Param id path int true "Song ID"
 // @Param song body storage.Song true "Updated song details"
 // @Success 200 {object} storage.Song
-// @Failure 400 {string} string "Invalid ID or JSON"
-// @Failure 500 {string} string "Failed to update song"
+// @Failure 400 {object} Problem "Invalid ID or JSON"
+// @Failure 404 {object} Problem "Song not found"
+// @Failure 409 {object} Problem "Song already exists"
+// @Failure 422 {object} Problem "Validation failed"
+// @Failure 500 {object} Problem "Failed to update song"
+// @Failure 504 {object} Problem "Request timed out"
 // @Router /songs/{id} [put]
 func (s *Server) UpdateSong() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id, err := strconv.Atoi(r.PathValue("id"))
 		if err != nil {
 			log.Printf("Error converting id to int: %v", err)
-			http.Error(w, "Invalid ID", http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
 			return
 		}
 
 		var song storage.Song
 		if err := json.NewDecoder(r.Body).Decode(&song); err != nil {
 			log.Printf("Error decoding JSON: %v", err)
-			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, codeInvalidJSON, "Invalid JSON")
 			return
 		}
 
 		song.ID = id
-		if err := s.app.UpdateSong(&song); err != nil {
+		err = s.app.UpdateSong(r.Context(), &song)
+		if handleWriteError(w, r, err, "Song") {
+			return
+		} else if err != nil {
 			log.Printf("Error updating song: %v", err)
-			http.Error(w, "Failed to update song", http.StatusInternalServerError)
+			writeInternalError(w, r, err, "Failed to update song")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(song)
+	}
+}
+
+// PatchSong godoc
+// @Summary Частичное обновление песни
+// @Description Частичное обновление песни: JSON Merge Patch (RFC 7396, application/merge-patch+json)
+// @Description или JSON Patch (RFC 6902, application/json-patch+json)
+// @Tags songs
+// @Accept  json
+// @Accept  application/merge-patch+json
+// @Accept  application/json-patch+json
+// @Produce  json
+// @Param id path int true "Song ID"
+// @Param patch body object true "Merge patch object or array of JSON Patch operations"
+// @Success 200 {object} storage.Song
+// @Failure 400 {object} Problem "Invalid ID or patch"
+// @Failure 404 {object} Problem "Song not found"
+// @Failure 409 {object} Problem "JSON Patch test operation failed or song already exists"
+// @Failure 415 {object} Problem "Unsupported patch format"
+// @Failure 422 {object} Problem "Validation failed"
+// @Failure 500 {object} Problem "Failed to patch song"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /songs/{id} [patch]
+func (s *Server) PatchSong() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Printf("Error converting id to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
+			return
+		}
+
+		var typ app.PatchType
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch mediaType {
+		case "application/merge-patch+json", "application/json", "":
+			typ = app.MergePatch
+		case "application/json-patch+json":
+			typ = app.JSONPatch
+		default:
+			writeError(w, r, http.StatusUnsupportedMediaType, codeUnsupportedMedia,
+				"Use application/merge-patch+json or application/json-patch+json")
+			return
+		}
+
+		patch, err := io.ReadAll(r.Body)
+		if err != nil {
+			log.Printf("Error reading body: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidPatch, "Invalid patch")
+			return
+		}
+
+		song, err := s.app.PatchSong(r.Context(), id, typ, patch)
+		switch {
+		case handleWriteError(w, r, err, "Song"):
+			return
+		case errors.Is(err, app.ErrInvalidPatch):
+			writeError(w, r, http.StatusBadRequest, codeInvalidPatch, err.Error())
+			return
+		case errors.Is(err, app.ErrPatchTestFailed):
+			writeError(w, r, http.StatusConflict, codePatchTestFailed, err.Error())
+			return
+		case err != nil:
+			log.Printf("Error patching song: %v", err)
+			writeInternalError(w, r, err, "Failed to patch song")
 			return
 		}
 
@@ -85,21 +236,27 @@ func (s *Server) UpdateSong() http.HandlerFunc {
 // @Tags songs
 // @Param id path int true "Song ID"
 // @Success 204
-// @Failure 400 {string} string "Invalid ID"
-// @Failure 500 {string} string "Failed to delete song"
+// @Failure 400 {object} Problem "Invalid ID"
+// @Failure 404 {object} Problem "Song not found"
+// @Failure 500 {object} Problem "Failed to delete song"
+// @Failure 504 {object} Problem "Request timed out"
 // @Router /songs/{id} [delete]
 func (s *Server) DeleteSong() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id, err := strconv.Atoi(r.PathValue("id"))
 		if err != nil {
 			log.Printf("Error converting id to int: %v", err)
-			http.Error(w, "Invalid ID", http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
 			return
 		}
 
-		if err := s.app.DeleteSong(id); err != nil {
+		err = s.app.DeleteSong(r.Context(), id)
+		if errors.Is(err, storage.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Song not found")
+			return
+		} else if err != nil {
 			log.Printf("Error deleting song: %v", err)
-			http.Error(w, "Failed to delete song", http.StatusInternalServerError)
+			writeInternalError(w, r, err, "Failed to delete song")
 			return
 		}
 
@@ -109,36 +266,162 @@ func (s *Server) DeleteSong() http.HandlerFunc {
 
 // GetAllSongs godoc
 // @Summary Получение песни или списка песен
-// @Description Получение песни или списка песен: limit - количество выводимых данных, offset - с какого элемента, можно фильтровать по любым полям
+// @Description Получение списка песен: limit - количество выводимых данных, offset - с какого элемента.
+// @Description Фильтрация по полям group, artistId, albumId, song, releaseDate, text, link: field=value или field[op]=value,
+// @Description операторы eq, ne, contains, prefix для текстовых полей, eq, ne для artistId и albumId и eq, ne, gt, gte, lt, lte для releaseDate.
+// @Description Дата может быть неполной (2006, 2006-07), releaseDate=2006 выбирает песни за весь 2006 год.
+// @Description releasedAfter и releasedBefore задают диапазон дат выхода: releasedAfter включительно, releasedBefore не включительно.
+// @Description sort - список полей через запятую, "-" перед полем означает сортировку по убыванию.
+// @Description tag - метка песни, можно передать несколько: tagMode=all (по умолчанию) выбирает песни со всеми метками, tagMode=any - хотя бы с одной.
+// @Description cursor - токен следующей страницы (nextCursor), при его передаче offset не используется.
+// @Description Ответ содержит общее количество найденных песен и ссылку на следующую страницу
 // @Tags songs
 // @Produce  json
 // @Param group query string false "Filter by group"
+// @Param artistId query int false "Filter by artist ID"
+// @Param albumId query int false "Filter by album ID"
 // @Param song query string false "Filter by song name"
-// @Param limit query int false "Limit the number of results"
+// @Param group[contains] query string false "Filter by group substring"
+// @Param song[contains] query string false "Filter by song name substring"
+// @Param releasedAfter query string false "Released on or after the date, e.g. 2000 or 16.07.2006"
+// @Param releasedBefore query string false "Released before the date, e.g. 2010"
+// @Param tag query []string false "Filter by tags" collectionFormat(multi)
+// @Param tagMode query string false "Match all or any of the tags" Enums(all, any)
+// @Param limit query int false "Limit the number of results, at most 100"
 // @Param offset query int false "Offset for pagination"
-// @Success 200 {array} storage.Song
-// @Failure 500 {string} string "Failed to get songs"
+// @Param cursor query string false "Cursor of the next page"
+// @Param sort query string false "Sort fields, e.g. -releaseDate,group"
+// @Success 200 {object} storage.SongPage
+// @Header 200 {integer} X-Total-Count "Total number of matching songs"
+// @Header 200 {string} Link "Link to the next page"
+// @Header 200 {string} X-Next-Cursor "Cursor of the next page"
+// @Failure 400 {object} Problem "Unknown filter or sort field, invalid tagMode, offset or cursor"
+// @Failure 500 {object} Problem "Failed to get songs"
+// @Failure 504 {object} Problem "Request timed out"
 // @Router /songs [get]
 func (s *Server) GetSongs() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		filter := make(map[string]string)
-		for key, values := range r.URL.Query() {
-			if key != "limit" && key != "offset" {
-				filter[key] = values[0]
-			}
+		s.listSongs(w, r, s.app.GetSongs)
+	}
+}
+
+// listSongs writes a page of songs returned by list, the query is built
+// from the filter, sort and pagination parameters of the request.
+func (s *Server) listSongs(w http.ResponseWriter, r *http.Request,
+	list func(ctx context.Context, q storage.ListQuery) (*storage.SongPage, error)) {
+	q, err := parseListQuery(r.URL.Query())
+	if err != nil {
+		log.Printf("Error parsing query: %v", err)
+		writeQueryError(w, r, err)
+		return
+	}
+
+	page, err := list(r.Context(), q)
+	if errors.Is(err, storage.ErrInvalidCursor) {
+		writeParamError(w, r, "cursor", err)
+		return
+	} else if err != nil {
+		log.Printf("Error getting songs: %v", err)
+		writeInternalError(w, r, err, "Failed to get songs")
+		return
+	}
+
+	if page.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+	}
+	page.Next = nextPageLink(r.URL, page.Offset, page.Limit, page.Total, &page.NextCursor)
+	if page.Next != "" {
+		w.Header().Set("Link", "<"+page.Next+`>; rel="next"`)
+	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(page)
+}
+
+// GetSongText godoc
+// @Summary Получение текста песни по куплетам
+// @Description Получение текста песни с пагинацией по куплетам: page - номер страницы, perPage - количество куплетов на странице (не больше 100)
+// @Tags songs
+// @Produce  json
+// @Param id path int true "Song ID"
+// @Param page query int false "Page number" default(1)
+// @Param perPage query int false "Verses per page" default(1)
+// @Success 200 {object} storage.SongText
+// @Success 301 "Song was merged into another one, Location points to it"
+// @Failure 400 {object} Problem "Invalid ID or pagination parameters"
+// @Failure 404 {object} Problem "Song not found"
+// @Failure 500 {object} Problem "Failed to get song text"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /songs/{id}/text [get]
+func (s *Server) GetSongText() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Printf("Error converting id to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
+			return
 		}
 
-		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		page, err := positiveParam(r.URL.Query(), "page", 1)
+		if err != nil {
+			log.Printf("Error parsing page: %v", err)
+			writeParamError(w, r, "page", err)
+			return
+		}
 
-		songs, err := s.app.GetSongs(filter, limit, offset)
+		perPage, err := positiveParam(r.URL.Query(), "perPage", 1)
 		if err != nil {
-			log.Printf("Error getting songs: %v", err)
-			http.Error(w, "Failed to get songs", http.StatusInternalServerError)
+			log.Printf("Error parsing perPage: %v", err)
+			writeParamError(w, r, "perPage", err)
+			return
+		}
+		perPage = min(perPage, maxLimit)
+
+		text, err := s.app.GetSongText(r.Context(), id, page, perPage)
+		if errors.Is(err, storage.ErrNotFound) {
+			if s.redirectSong(w, r, id, "/text") {
+				return
+			}
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Song not found")
 			return
+		} else if err != nil {
+			log.Printf("Error getting song text: %v", err)
+			writeInternalError(w, r, err, "Failed to get song text")
+			return
+		}
+
+		if page < (text.Total+perPage-1)/perPage {
+			text.Next = pageLink(r.URL, page+1, perPage)
+		}
+		if page > 1 {
+			text.Prev = pageLink(r.URL, page-1, perPage)
 		}
 
 		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(songs)
+		json.NewEncoder(w).Encode(text)
+	}
+}
+
+func positiveParam(query url.Values, name string, def int) (int, error) {
+	value := query.Get(name)
+	if value == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
 	}
+	if n < 1 {
+		return 0, errors.New(name + " must be positive")
+	}
+	return n, nil
+}
+
+func pageLink(u *url.URL, page, perPage int) string {
+	query := u.Query()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("perPage", strconv.Itoa(perPage))
+	return (&url.URL{Path: u.Path, RawQuery: query.Encode()}).String()
 }
@@ -0,0 +1,367 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fevse/songlib/internal/app"
+	"github.com/fevse/songlib/internal/storage"
+)
+
+// newTestHandler serves the API routes over the memory storage holding
+// songs, their ids are filled in. Song details are not looked up.
+func newTestHandler(t *testing.T, songs ...*storage.Song) http.Handler {
+	t.Helper()
+	repo := storage.NewMemoryStorage()
+	for _, song := range songs {
+		if err := repo.Create(context.Background(), song); err != nil {
+			t.Fatalf("Create(%s - %s): %v", song.Group, song.Song, err)
+		}
+	}
+	return NewServer(app.NewSongLibApp(repo, ""), "", "", 0, time.Hour).routes()
+}
+
+// newRequest builds a request with the body sent as JSON.
+func newRequest(method, target, body string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	return r
+}
+
+// serve sends the request to h and decodes the response into v, errors
+// into a *Problem. v may be nil.
+func serve(t *testing.T, h http.Handler, r *http.Request, v any) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if v != nil && w.Body.Len() > 0 {
+		if err := json.NewDecoder(w.Body).Decode(v); err != nil {
+			t.Fatalf("%s %s: decoding response: %v", r.Method, r.URL, err)
+		}
+	}
+	return w
+}
+
+func TestGetSong(t *testing.T) {
+	h := newTestHandler(t, &storage.Song{Group: "Muse", Song: "Hole"})
+
+	var song storage.Song
+	if w := serve(t, h, newRequest(http.MethodGet, "/songs/1", ""), &song); w.Code != http.StatusOK || song.Song != "Hole" {
+		t.Errorf("GET /songs/1 = %d %+v, want the song", w.Code, song)
+	}
+
+	tests := []struct {
+		target string
+		status int
+		code   string
+	}{
+		{"/songs/2", http.StatusNotFound, codeNotFound},
+		{"/songs/x", http.StatusBadRequest, codeInvalidID},
+	}
+	for _, tt := range tests {
+		var p Problem
+		w := serve(t, h, newRequest(http.MethodGet, tt.target, ""), &p)
+		if w.Code != tt.status || p.Code != tt.code || p.Status != tt.status || p.Instance != tt.target {
+			t.Errorf("GET %s = %d %+v, want %d %s", tt.target, w.Code, p, tt.status, tt.code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Errorf("GET %s Content-Type = %q, want application/problem+json", tt.target, ct)
+		}
+	}
+}
+
+func TestGetSongText(t *testing.T) {
+	song := &storage.Song{Group: "Muse", Song: "Hole", Text: "one\n\ntwo\n\nthree\n\nfour\n\nfive"}
+	h := newTestHandler(t, song)
+
+	tests := []struct {
+		query      string
+		status     int
+		verses     []string
+		next, prev string
+	}{
+		{"", http.StatusOK, []string{"one"}, "/songs/1/text?page=2&perPage=1", ""},
+		{"?page=2&perPage=2", http.StatusOK, []string{"three", "four"}, "/songs/1/text?page=3&perPage=2", "/songs/1/text?page=1&perPage=2"},
+		{"?page=3&perPage=2", http.StatusOK, []string{"five"}, "", "/songs/1/text?page=2&perPage=2"},
+		{"?page=4&perPage=2", http.StatusOK, []string{}, "", "/songs/1/text?page=3&perPage=2"},
+		{"?perPage=1000", http.StatusOK, []string{"one", "two", "three", "four", "five"}, "", ""},
+		// page*perPage overflows int.
+		{"?page=4611686018427387904&perPage=4", http.StatusOK, []string{}, "", "/songs/1/text?page=4611686018427387903&perPage=4"},
+		{"?page=2&perPage=9223372036854775807", http.StatusOK, []string{}, "", "/songs/1/text?page=1&perPage=100"},
+		{"?page=0", http.StatusBadRequest, nil, "", ""},
+		{"?perPage=x", http.StatusBadRequest, nil, "", ""},
+	}
+	for _, tt := range tests {
+		var text storage.SongText
+		w := serve(t, h, newRequest(http.MethodGet, "/songs/1/text"+tt.query, ""), &text)
+		if w.Code != tt.status {
+			t.Errorf("GET %s status = %d, want %d", tt.query, w.Code, tt.status)
+			continue
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+		if !reflect.DeepEqual(text.Verses, tt.verses) || text.Next != tt.next || text.Prev != tt.prev {
+			t.Errorf("GET %s = %q next %q prev %q, want %q next %q prev %q",
+				tt.query, text.Verses, text.Next, text.Prev, tt.verses, tt.next, tt.prev)
+		}
+	}
+
+	if w := serve(t, h, newRequest(http.MethodGet, "/songs/2/text", ""), nil); w.Code != http.StatusNotFound {
+		t.Errorf("GET of a missing song status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestGetSongs(t *testing.T) {
+	h := newTestHandler(t,
+		&storage.Song{Group: "Muse", Song: "Hole"},
+		&storage.Song{Group: "Kino", Song: "Kukushka"},
+		&storage.Song{Group: "Muse", Song: "Uprising"},
+	)
+
+	var page storage.SongPage
+	w := serve(t, h, newRequest(http.MethodGet, "/songs?group=Muse&sort=-song&limit=1&offset=0", ""), &page)
+	if w.Code != http.StatusOK || page.Total != 2 || len(page.Items) != 1 || page.Items[0].Song != "Uprising" {
+		t.Fatalf("GET /songs = %d %+v, want the first of 2 songs of Muse", w.Code, page)
+	}
+	if want := "/songs?group=Muse&limit=1&offset=1&sort=-song"; page.Next != want || w.Header().Get("Link") != "<"+want+`>; rel="next"` {
+		t.Errorf("next = %q, Link %q, want %q", page.Next, w.Header().Get("Link"), want)
+	}
+	if total := w.Header().Get("X-Total-Count"); total != "2" {
+		t.Errorf("X-Total-Count = %q, want 2", total)
+	}
+
+	// Without an offset the next page is linked by cursor.
+	w = serve(t, h, newRequest(http.MethodGet, "/songs?limit=2", ""), &page)
+	if cursor := w.Header().Get("X-Next-Cursor"); cursor == "" || page.Next != "/songs?cursor="+cursor+"&limit=2" {
+		t.Fatalf("next = %q, X-Next-Cursor %q, want a link with the cursor", page.Next, cursor)
+	}
+	var next storage.SongPage
+	serve(t, h, newRequest(http.MethodGet, page.Next, ""), &next)
+	if len(next.Items) != 1 || next.Items[0].Song != "Uprising" || next.Next != "" {
+		t.Errorf("page after %q = %+v, want the last song", page.Next, next)
+	}
+
+	for _, target := range []string{"/songs?genre=rock", "/songs?group[gt]=M", "/songs?sort=genre"} {
+		var p Problem
+		if w := serve(t, h, newRequest(http.MethodGet, target, ""), &p); w.Code != http.StatusBadRequest || p.Code != codeInvalidFilter {
+			t.Errorf("GET %s = %d %s, want %d %s", target, w.Code, p.Code, http.StatusBadRequest, codeInvalidFilter)
+		}
+	}
+	var p Problem
+	if w := serve(t, h, newRequest(http.MethodGet, "/songs?releasedAfter=yesterday", ""), &p); w.Code != http.StatusBadRequest || p.Code != codeInvalidParameter {
+		t.Errorf("GET with an invalid date = %d %s, want %d %s", w.Code, p.Code, http.StatusBadRequest, codeInvalidParameter)
+	}
+	if w := serve(t, h, newRequest(http.MethodGet, "/songs?cursor=x", ""), nil); w.Code != http.StatusBadRequest {
+		t.Errorf("GET with an invalid cursor status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetSongsByReleaseDate(t *testing.T) {
+	h := newTestHandler(t,
+		&storage.Song{Group: "Muse", Song: "Hole", ReleaseDate: "2006-07-16", ReleaseDatePrecision: "day"},
+		&storage.Song{Group: "Muse", Song: "Uprising", ReleaseDate: "2009-09-07", ReleaseDatePrecision: "day"},
+		&storage.Song{Group: "Muse", Song: "Starlight"},
+		&storage.Song{Group: "Muse", Song: "Dead Star", ReleaseDate: "2002-06-09", ReleaseDatePrecision: "day"},
+	)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"releaseDate=2006", []string{"Hole"}},
+		{"releaseDate[ne]=2006", []string{"Uprising", "Dead Star"}},
+		{"releaseDate[ne]=07.2006", []string{"Uprising", "Dead Star"}},
+		{"releaseDate[ne]=2006-07-17", []string{"Hole", "Uprising", "Dead Star"}},
+		{"releasedAfter=2003&releasedBefore=2009", []string{"Hole"}},
+		// releasedAfter is inclusive.
+		{"releasedAfter=2002&releasedBefore=2009", []string{"Hole", "Dead Star"}},
+	}
+	for _, tt := range tests {
+		var page storage.SongPage
+		if w := serve(t, h, newRequest(http.MethodGet, "/songs?"+tt.query, ""), &page); w.Code != http.StatusOK {
+			t.Errorf("GET /songs?%s status = %d, want %d", tt.query, w.Code, http.StatusOK)
+			continue
+		}
+		got := []string{}
+		for _, song := range page.Items {
+			got = append(got, song.Song)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GET /songs?%s = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestCreateSong(t *testing.T) {
+	h := newTestHandler(t)
+
+	var song storage.Song
+	if w := serve(t, h, newRequest(http.MethodPost, "/songs", `{"group": "Muse", "song": "Hole"}`), &song); w.Code != http.StatusCreated || song.ID != 1 {
+		t.Errorf("POST /songs = %d %+v, want the song created", w.Code, song)
+	}
+
+	var p Problem
+	w := serve(t, h, newRequest(http.MethodPost, "/songs", `{"group": "", "song": "Hole", "link": "example.com"}`), &p)
+	if w.Code != http.StatusUnprocessableEntity || p.Code != codeValidation || len(p.Errors) != 2 {
+		t.Errorf("POST of an invalid song = %d %+v, want %d with 2 errors", w.Code, p, http.StatusUnprocessableEntity)
+	}
+	if w := serve(t, h, newRequest(http.MethodPost, "/songs", `{`), &p); w.Code != http.StatusBadRequest || p.Code != codeInvalidJSON {
+		t.Errorf("POST of invalid JSON = %d %s, want %d %s", w.Code, p.Code, http.StatusBadRequest, codeInvalidJSON)
+	}
+}
+
+func TestCreateSongOnConflict(t *testing.T) {
+	h := newTestHandler(t, &storage.Song{Group: "Кино", Song: "Группа крови", Text: "Теплое место"})
+
+	tests := []struct {
+		query, body string
+		status      int
+		code        string
+		text        string
+	}{
+		{"?onConflict=error", `{"group": "KINO", "song": "Gruppa krovi"}`, http.StatusConflict, codeConflict, ""},
+		{"?onConflict=skip", `{"group": "KINO", "song": "Gruppa krovi"}`, http.StatusBadRequest, codeInvalidParameter, ""},
+		{"?onConflict=ignore", `{"group": "KINO", "song": "Gruppa krovi", "text": "New"}`, http.StatusOK, "", "Теплое место"},
+		{"?onConflict=update", `{"group": "Кино", "song": "Группа крови", "text": "New"}`, http.StatusOK, "", "New"},
+		{"?onConflict=update", `{"group": "Muse", "song": "Hole", "text": "Sky"}`, http.StatusCreated, "", "Sky"},
+		{"?onConflict=ignore", `{"group": "Muse", "song": "Hole", "text": "Other"}`, http.StatusOK, "", "Sky"},
+		// Without onConflict the song is created anyway.
+		{"", `{"group": "KINO", "song": "Gruppa krovi", "text": "Copy"}`, http.StatusCreated, "", "Copy"},
+	}
+	for _, tt := range tests {
+		var song struct {
+			storage.Song
+			Code string `json:"code"`
+		}
+		w := serve(t, h, newRequest(http.MethodPost, "/songs"+tt.query, tt.body), &song)
+		if w.Code != tt.status || song.Code != tt.code || song.Text != tt.text {
+			t.Errorf("POST /songs%s %s = %d %s %q, want %d %s %q", tt.query, tt.body, w.Code, song.Code, song.Text, tt.status, tt.code, tt.text)
+		}
+	}
+
+	var page storage.SongPage
+	if serve(t, h, newRequest(http.MethodGet, "/songs", ""), &page); page.Total != 3 || page.Items[0].Group != "Кино" {
+		t.Errorf("songs = %+v, want 3 songs with the first name kept", page)
+	}
+
+	// A storage keeping songs unique refuses the duplicate.
+	repo := storage.NewMemoryStorage()
+	if err := repo.SetUniqueSongs(context.Background(), true); err != nil {
+		t.Fatal(err)
+	}
+	h = NewServer(app.NewSongLibApp(repo, ""), "", "", 0, time.Hour).routes()
+	serve(t, h, newRequest(http.MethodPost, "/songs", `{"group": "Кино", "song": "Группа крови"}`), nil)
+	var p Problem
+	if w := serve(t, h, newRequest(http.MethodPost, "/songs", `{"group": "KINO", "song": "Gruppa krovi"}`), &p); w.Code != http.StatusConflict || p.Code != codeConflict {
+		t.Errorf("POST of a duplicate with unique songs = %d %s, want %d %s", w.Code, p.Code, http.StatusConflict, codeConflict)
+	}
+}
+
+func TestUpdateSong(t *testing.T) {
+	h := newTestHandler(t, &storage.Song{Group: "Muse", Song: "Hole"})
+
+	var song storage.Song
+	w := serve(t, h, newRequest(http.MethodPut, "/songs/1", `{"group": "Muse", "song": "Hole", "releaseDate": "16.07.2006"}`), &song)
+	if w.Code != http.StatusOK || song.ReleaseDate != "2006-07-16" || song.ReleaseDatePrecision != "day" {
+		t.Errorf("PUT /songs/1 = %d %+v, want the song updated", w.Code, song)
+	}
+	// A new date sent back with the stored precision takes its own.
+	body := `{"group": "Muse", "song": "Hole", "releaseDate": "2007", "releaseDatePrecision": "day"}`
+	if w := serve(t, h, newRequest(http.MethodPut, "/songs/1", body), &song); w.Code != http.StatusOK || song.ReleaseDate != "2007-01-01" || song.ReleaseDatePrecision != "year" {
+		t.Errorf("PUT /songs/1 with a new year = %d %+v, want the year precision", w.Code, song)
+	}
+
+	tests := []struct {
+		method, target, body string
+		status               int
+	}{
+		{http.MethodPut, "/songs/2", `{"group": "Muse", "song": "Hole"}`, http.StatusNotFound},
+		{http.MethodPut, "/songs/1", `{"group": "Muse"}`, http.StatusUnprocessableEntity},
+		{http.MethodDelete, "/songs/1", "", http.StatusNoContent},
+		{http.MethodDelete, "/songs/1", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := serve(t, h, newRequest(tt.method, tt.target, tt.body), nil); w.Code != tt.status {
+			t.Errorf("%s %s status = %d, want %d", tt.method, tt.target, w.Code, tt.status)
+		}
+	}
+}
+
+func TestPatchSong(t *testing.T) {
+	h := newTestHandler(t, &storage.Song{Group: "Muse", Song: "Hole", Text: "lyrics"})
+
+	tests := []struct {
+		contentType, body string
+		status            int
+		song              string
+	}{
+		{"application/merge-patch+json", `{"song": "Uprising", "text": null}`, http.StatusOK, "Uprising"},
+		{"application/json-patch+json", `[{"op": "replace", "path": "/song", "value": "Starlight"}]`, http.StatusOK, "Starlight"},
+		{"application/json-patch+json", `[{"op": "test", "path": "/song", "value": "Hole"}]`, http.StatusConflict, ""},
+		{"application/json-patch+json", `[{"op": "swap", "path": "/song"}]`, http.StatusBadRequest, ""},
+		{"application/merge-patch+json", `{"song": ""}`, http.StatusUnprocessableEntity, ""},
+		{"text/plain", `song`, http.StatusUnsupportedMediaType, ""},
+	}
+	for _, tt := range tests {
+		r := newRequest(http.MethodPatch, "/songs/1", tt.body)
+		r.Header.Set("Content-Type", tt.contentType)
+		var song storage.Song
+		w := serve(t, h, r, &song)
+		if w.Code != tt.status || (tt.song != "" && song.Song != tt.song) {
+			t.Errorf("PATCH %s = %d %q, want %d %q", tt.body, w.Code, song.Song, tt.status, tt.song)
+		}
+	}
+
+	var song storage.Song
+	serve(t, h, newRequest(http.MethodGet, "/songs/1", ""), &song)
+	if song.Song != "Starlight" || song.Text != "" {
+		t.Errorf("patched song = %+v, want title Starlight without text", song)
+	}
+}
+
+func TestListLimit(t *testing.T) {
+	h := newTestHandler(t)
+
+	targets := []string{"/songs", "/songs/search?q=x", "/songs/fuzzy?q=x", "/songs/duplicates", "/artists", "/albums", "/playlists"}
+	for _, target := range targets {
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		var page struct {
+			Limit int `json:"limit"`
+		}
+		if w := serve(t, h, newRequest(http.MethodGet, target+sep+"limit=1000", ""), &page); w.Code != http.StatusOK || page.Limit != maxLimit {
+			t.Errorf("GET %s with limit 1000 = %d limit %d, want %d", target, w.Code, page.Limit, maxLimit)
+		}
+	}
+}
+
+func TestListOffset(t *testing.T) {
+	h := newTestHandler(t, &storage.Song{Group: "Muse", Song: "Hole"})
+
+	targets := []string{"/songs", "/playlists", "/songs/search?q=hole", "/albums", "/artists", "/songs/duplicates"}
+	for _, target := range targets {
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		for _, offset := range []string{"x", "-1", "1.5"} {
+			if w := serve(t, h, newRequest(http.MethodGet, target+sep+"offset="+offset, ""), nil); w.Code != http.StatusBadRequest {
+				t.Errorf("GET %s with offset %s status = %d, want %d", target, offset, w.Code, http.StatusBadRequest)
+			}
+		}
+		if w := serve(t, h, newRequest(http.MethodGet, target+sep+"offset=5", ""), nil); w.Code != http.StatusOK {
+			t.Errorf("GET %s with offset 5 status = %d, want %d", target, w.Code, http.StatusOK)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+// maxIdempotencyKey is the length of the idempotency_key column.
+const maxIdempotencyKey = 255
+
+// idempotencyLeaseMargin is added to the request timeout to get how long
+// a key stays reserved by a request in progress.
+const idempotencyLeaseMargin = 30 * time.Second
+
+// idempotent makes retries of a request with the same Idempotency-Key
+// header safe: the first response is saved for s.idempotencyTTL and
+// replayed to the retries. A key reused for another request is rejected
+// with 422. Server errors are not saved, the request can be retried.
+// While the request is in progress the key is only leased for the request
+// timeout and a margin, so a key left behind by a crash soon expires.
+func (s *Server) idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKey {
+			writeParamError(w, r, "Idempotency-Key", errors.New("must be at most 255 characters"))
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			log.Printf("Error reading request body: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidJSON, "Invalid JSON")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		rec := storage.IdempotencyRecord{
+			Key:         key,
+			RequestHash: requestHash(r, body),
+			ExpiresAt:   time.Now().Add(s.idempotencyLease()),
+		}
+		saved, err := s.app.ReserveIdempotencyKey(r.Context(), rec)
+		if err != nil {
+			log.Printf("Error reserving idempotency key: %v", err)
+			writeInternalError(w, r, err, "Failed to check Idempotency-Key")
+			return
+		}
+		if saved != nil {
+			replay(w, r, rec, saved)
+			return
+		}
+
+		// The key is released unless a response is saved, also when the
+		// handler panics. Both happen even if the request ran out of time.
+		ctx := context.WithoutCancel(r.Context())
+		done := false
+		defer func() {
+			if done {
+				return
+			}
+			if err := s.app.ReleaseIdempotencyKey(ctx, key); err != nil {
+				log.Printf("Error releasing idempotency key: %v", err)
+			}
+		}()
+
+		rw := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+		if rw.status >= http.StatusInternalServerError {
+			return
+		}
+
+		rec.Status, rec.ContentType, rec.Body = rw.status, w.Header().Get("Content-Type"), rw.body.String()
+		rec.ExpiresAt = time.Now().Add(s.idempotencyTTL)
+		if err := s.app.SaveIdempotentResponse(ctx, rec); err != nil {
+			log.Printf("Error saving idempotent response: %v", err)
+			return
+		}
+		done = true
+	})
+}
+
+// idempotencyLease is how long a request in progress holds its key.
+// Without a request timeout a request may run for any time, the key is
+// then held for the whole TTL.
+func (s *Server) idempotencyLease() time.Duration {
+	if s.requestTimeout <= 0 {
+		return s.idempotencyTTL
+	}
+	return min(s.requestTimeout+idempotencyLeaseMargin, s.idempotencyTTL)
+}
+
+// replay answers a repeated request with the response saved for its key.
+func replay(w http.ResponseWriter, r *http.Request, rec storage.IdempotencyRecord, saved *storage.IdempotencyRecord) {
+	switch {
+	case saved.RequestHash != rec.RequestHash:
+		writeError(w, r, http.StatusUnprocessableEntity, codeIdempotencyKeyReused,
+			"Idempotency-Key was used for another request")
+	case saved.Status == 0:
+		writeError(w, r, http.StatusConflict, codeIdempotencyInProgress,
+			"Request with this Idempotency-Key is in progress")
+	default:
+		if saved.ContentType != "" {
+			w.Header().Set("Content-Type", saved.ContentType)
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(saved.Status)
+		io.WriteString(w, saved.Body)
+	}
+}
+
+// requestHash identifies a request by its method, URL and body.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingWriter keeps a copy of the response it writes.
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
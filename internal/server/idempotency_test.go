@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fevse/songlib/internal/app"
+	"github.com/fevse/songlib/internal/storage"
+)
+
+func TestIdempotentCreateSong(t *testing.T) {
+	repo := storage.NewMemoryStorage()
+	h := NewServer(app.NewSongLibApp(repo, ""), "", "", 0, time.Hour).routes()
+
+	post := func(key, body string) *http.Request {
+		r := newRequest(http.MethodPost, "/songs", body)
+		r.Header.Set("Idempotency-Key", key)
+		return r
+	}
+
+	w := serve(t, h, post("k1", `{"group": "Muse", "song": "Hole"}`), nil)
+	first := w.Body.String()
+	if w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("first POST = %d, replayed %q, want %d", w.Code, w.Header().Get("Idempotent-Replayed"), http.StatusCreated)
+	}
+	w = serve(t, h, post("k1", `{"group": "Muse", "song": "Hole"}`), nil)
+	if w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "true" || w.Body.String() != first {
+		t.Errorf("retried POST = %d %q, replayed %q, want the first response", w.Code, w.Body, w.Header().Get("Idempotent-Replayed"))
+	}
+	if page, _ := repo.GetList(context.Background(), storage.ListQuery{Limit: 10}); page.Total != 1 {
+		t.Errorf("songs after a retry = %d, want 1", page.Total)
+	}
+
+	// Client errors are saved as well.
+	serve(t, h, post("k2", `{"group": "Muse"}`), nil)
+	if w := serve(t, h, post("k2", `{"group": "Muse"}`), nil); w.Code != http.StatusUnprocessableEntity || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retried invalid POST = %d, replayed %q, want %d", w.Code, w.Header().Get("Idempotent-Replayed"), http.StatusUnprocessableEntity)
+	}
+
+	// A request holding the key has not answered yet.
+	body := `{"group": "Kino", "song": "Kukushka"}`
+	r := post("k3", body)
+	rec := storage.IdempotencyRecord{Key: "k3", RequestHash: requestHash(r, []byte(body)), ExpiresAt: time.Now().Add(time.Hour)}
+	if _, err := repo.ReserveKey(context.Background(), rec); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		r      *http.Request
+		status int
+		code   string
+	}{
+		{post("k1", `{"group": "Muse", "song": "Uprising"}`), http.StatusUnprocessableEntity, codeIdempotencyKeyReused},
+		{r, http.StatusConflict, codeIdempotencyInProgress},
+		{post(strings.Repeat("k", 256), body), http.StatusBadRequest, codeInvalidParameter},
+	}
+	for _, tt := range tests {
+		var p Problem
+		if w := serve(t, h, tt.r, &p); w.Code != tt.status || p.Code != tt.code {
+			t.Errorf("POST with key %.10s = %d %s, want %d %s", tt.r.Header.Get("Idempotency-Key"), w.Code, p.Code, tt.status, tt.code)
+		}
+	}
+
+	// Without a key nothing is saved.
+	for range 2 {
+		serve(t, h, newRequest(http.MethodPost, "/songs?onConflict=ignore", `{"group": "Kino", "song": "Kukushka"}`), nil)
+	}
+	if page, _ := repo.GetList(context.Background(), storage.ListQuery{Limit: 10}); page.Total != 2 {
+		t.Errorf("songs = %d, want 2", page.Total)
+	}
+}
+
+func TestIdempotencyLease(t *testing.T) {
+	tests := []struct {
+		timeout, ttl time.Duration
+		want         time.Duration
+	}{
+		{0, time.Hour, time.Hour},
+		{10 * time.Second, time.Hour, 40 * time.Second},
+		{time.Hour, 10 * time.Minute, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		s := NewServer(nil, "", "", tt.timeout, tt.ttl)
+		if got := s.idempotencyLease(); got != tt.want {
+			t.Errorf("idempotencyLease(%v, %v) = %v, want %v", tt.timeout, tt.ttl, got, tt.want)
+		}
+	}
+}
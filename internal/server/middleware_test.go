@@ -0,0 +1,19 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWithTimeout(t *testing.T) {
+	h := withTimeout(time.Millisecond, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		writeInternalError(w, r, r.Context().Err(), "Failed to get songs")
+	}))
+
+	var p Problem
+	if w := serve(t, h, newRequest(http.MethodGet, "/songs", ""), &p); w.Code != http.StatusGatewayTimeout || p.Code != codeTimeout {
+		t.Errorf("GET past the deadline = %d %s, want %d %s", w.Code, p.Code, http.StatusGatewayTimeout, codeTimeout)
+	}
+}
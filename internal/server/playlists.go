@@ -0,0 +1,352 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+// CreatePlaylist godoc
+// @Summary Создание плейлиста
+// @Description Создание нового пустого плейлиста, песни добавляются через POST /playlists/{id}/entries
+// @Tags playlists
+// @Accept  json
+// @Produce  json
+// @Param playlist body storage.Playlist true "Playlist to create"
+// @Success 201 {object} storage.Playlist
+// @Failure 400 {object} Problem "Invalid JSON"
+// @Failure 422 {object} Problem "Validation failed"
+// @Failure 500 {object} Problem "Failed to create playlist"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /playlists [post]
+func (s *Server) CreatePlaylist() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var playlist storage.Playlist
+		if err := json.NewDecoder(r.Body).Decode(&playlist); err != nil {
+			log.Printf("Error decoding JSON: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidJSON, "Invalid JSON")
+			return
+		}
+
+		playlist.Entries = nil
+		err := s.app.CreatePlaylist(r.Context(), &playlist)
+		if handleWriteError(w, r, err, "Playlist") {
+			return
+		} else if err != nil {
+			log.Printf("Error creating playlist: %v", err)
+			writeInternalError(w, r, err, "Failed to create playlist")
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(playlist)
+	}
+}
+
+// GetPlaylist godoc
+// @Summary Получение плейлиста по ID
+// @Description Получение плейлиста вместе с песнями в порядке позиций, позиции начинаются с 1
+// @Tags playlists
+// @Produce  json
+// @Param id path int true "Playlist ID"
+// @Success 200 {object} storage.Playlist
+// @Failure 400 {object} Problem "Invalid ID"
+// @Failure 404 {object} Problem "Playlist not found"
+// @Failure 500 {object} Problem "Failed to get playlist"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /playlists/{id} [get]
+func (s *Server) GetPlaylist() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Printf("Error converting id to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
+			return
+		}
+
+		playlist, err := s.app.GetPlaylist(r.Context(), id)
+		if errors.Is(err, storage.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Playlist not found")
+			return
+		} else if err != nil {
+			log.Printf("Error getting playlist: %v", err)
+			writeInternalError(w, r, err, "Failed to get playlist")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(playlist)
+	}
+}
+
+// UpdatePlaylist godoc
+// @Summary Переименование плейлиста
+// @Description Меняет название плейлиста, песни плейлиста не меняются
+// @Tags playlists
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Playlist ID"
+// @Param playlist body storage.Playlist true "Updated playlist"
+// @Success 200 {object} storage.Playlist
+// @Failure 400 {object} Problem "Invalid ID or JSON"
+// @Failure 404 {object} Problem "Playlist not found"
+// @Failure 422 {object} Problem "Validation failed"
+// @Failure 500 {object} Problem "Failed to update playlist"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /playlists/{id} [put]
+func (s *Server) UpdatePlaylist() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Printf("Error converting id to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
+			return
+		}
+
+		var playlist storage.Playlist
+		if err := json.NewDecoder(r.Body).Decode(&playlist); err != nil {
+			log.Printf("Error decoding JSON: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidJSON, "Invalid JSON")
+			return
+		}
+
+		playlist.ID = id
+		playlist.Entries = nil
+		err = s.app.UpdatePlaylist(r.Context(), &playlist)
+		if handleWriteError(w, r, err, "Playlist") {
+			return
+		} else if err != nil {
+			log.Printf("Error updating playlist: %v", err)
+			writeInternalError(w, r, err, "Failed to update playlist")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(playlist)
+	}
+}
+
+// DeletePlaylist godoc
+// @Summary Удаление плейлиста
+// @Description Удаляет плейлист по ID, песни остаются в библиотеке
+// @Tags playlists
+// @Param id path int true "Playlist ID"
+// @Success 204
+// @Failure 400 {object} Problem "Invalid ID"
+// @Failure 404 {object} Problem "Playlist not found"
+// @Failure 500 {object} Problem "Failed to delete playlist"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /playlists/{id} [delete]
+func (s *Server) DeletePlaylist() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Printf("Error converting id to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
+			return
+		}
+
+		err = s.app.DeletePlaylist(r.Context(), id)
+		if errors.Is(err, storage.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Playlist not found")
+			return
+		} else if err != nil {
+			log.Printf("Error deleting playlist: %v", err)
+			writeInternalError(w, r, err, "Failed to delete playlist")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GetPlaylists godoc
+// @Summary Получение списка плейлистов
+// @Description Получение списка плейлистов без песен в порядке создания: name - часть названия,
+// @Description limit - количество выводимых данных, offset - с какого элемента
+// @Tags playlists
+// @Produce  json
+// @Param name query string false "Filter by name substring"
+// @Param limit query int false "Limit the number of results, at most 100"
+// @Param offset query int false "Offset for pagination"
+// @Success 200 {object} storage.PlaylistPage
+// @Header 200 {integer} X-Total-Count "Total number of matching playlists"
+// @Header 200 {string} Link "Link to the next page"
+// @Failure 400 {object} Problem "Invalid offset"
+// @Failure 500 {object} Problem "Failed to get playlists"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /playlists [get]
+func (s *Server) GetPlaylists() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := parseLimit(r.URL.Query())
+		offset, err := parseOffset(r.URL.Query())
+		if err != nil {
+			log.Printf("Error parsing offset: %v", err)
+			writeParamError(w, r, "offset", err)
+			return
+		}
+
+		page, err := s.app.GetPlaylists(r.Context(), storage.PlaylistQuery{
+			Name:   r.URL.Query().Get("name"),
+			Limit:  limit,
+			Offset: offset,
+		})
+		if err != nil {
+			log.Printf("Error getting playlists: %v", err)
+			writeInternalError(w, r, err, "Failed to get playlists")
+			return
+		}
+
+		page.Next = nextPageLink(r.URL, page.Offset, page.Limit, page.Total, nil)
+		if page.Next != "" {
+			w.Header().Set("Link", "<"+page.Next+`>; rel="next"`)
+		}
+		w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(page)
+	}
+}
+
+// InsertPlaylistEntry godoc
+// @Summary Добавление песни в плейлист
+// @Description Вставляет песню на позицию position, следующие песни сдвигаются вниз. Без position песня добавляется в конец.
+// @Description Одна песня может быть в плейлисте несколько раз
+// @Tags playlists
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Playlist ID"
+// @Param entry body storage.PlaylistInsert true "Song and position"
+// @Success 200 {object} storage.Playlist
+// @Failure 400 {object} Problem "Invalid ID or JSON"
+// @Failure 404 {object} Problem "Playlist not found"
+// @Failure 422 {object} Problem "Unknown song or position out of range"
+// @Failure 500 {object} Problem "Failed to add song to playlist"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /playlists/{id}/entries [post]
+func (s *Server) InsertPlaylistEntry() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Printf("Error converting id to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
+			return
+		}
+
+		var insert storage.PlaylistInsert
+		if err := json.NewDecoder(r.Body).Decode(&insert); err != nil {
+			log.Printf("Error decoding JSON: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidJSON, "Invalid JSON")
+			return
+		}
+
+		playlist, err := s.app.InsertEntry(r.Context(), id, insert)
+		if handleWriteError(w, r, err, "Playlist") {
+			return
+		} else if err != nil {
+			log.Printf("Error inserting playlist entry: %v", err)
+			writeInternalError(w, r, err, "Failed to add song to playlist")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(playlist)
+	}
+}
+
+// MovePlaylistEntry godoc
+// @Summary Перемещение песни в плейлисте
+// @Description Переносит запись плейлиста на позицию position, песни между старой и новой позицией сдвигаются на одну
+// @Tags playlists
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Playlist ID"
+// @Param entryId path int true "Entry ID"
+// @Param move body storage.PlaylistMove true "New position"
+// @Success 200 {object} storage.Playlist
+// @Failure 400 {object} Problem "Invalid ID or JSON"
+// @Failure 404 {object} Problem "Playlist entry not found"
+// @Failure 422 {object} Problem "Position out of range"
+// @Failure 500 {object} Problem "Failed to move playlist entry"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /playlists/{id}/entries/{entryId} [patch]
+func (s *Server) MovePlaylistEntry() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Printf("Error converting id to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
+			return
+		}
+		entryID, err := strconv.Atoi(r.PathValue("entryId"))
+		if err != nil {
+			log.Printf("Error converting entryId to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid entry ID")
+			return
+		}
+
+		var move storage.PlaylistMove
+		if err := json.NewDecoder(r.Body).Decode(&move); err != nil {
+			log.Printf("Error decoding JSON: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidJSON, "Invalid JSON")
+			return
+		}
+
+		playlist, err := s.app.MoveEntry(r.Context(), id, entryID, move)
+		if handleWriteError(w, r, err, "Playlist entry") {
+			return
+		} else if err != nil {
+			log.Printf("Error moving playlist entry: %v", err)
+			writeInternalError(w, r, err, "Failed to move playlist entry")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(playlist)
+	}
+}
+
+// RemovePlaylistEntry godoc
+// @Summary Удаление песни из плейлиста
+// @Description Убирает запись из плейлиста, следующие песни сдвигаются вверх. Сама песня остается в библиотеке
+// @Tags playlists
+// @Param id path int true "Playlist ID"
+// @Param entryId path int true "Entry ID"
+// @Success 204
+// @Failure 400 {object} Problem "Invalid ID"
+// @Failure 404 {object} Problem "Playlist entry not found"
+// @Failure 500 {object} Problem "Failed to remove playlist entry"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /playlists/{id}/entries/{entryId} [delete]
+func (s *Server) RemovePlaylistEntry() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Printf("Error converting id to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
+			return
+		}
+		entryID, err := strconv.Atoi(r.PathValue("entryId"))
+		if err != nil {
+			log.Printf("Error converting entryId to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid entry ID")
+			return
+		}
+
+		err = s.app.RemoveEntry(r.Context(), id, entryID)
+		if errors.Is(err, storage.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Playlist entry not found")
+			return
+		} else if err != nil {
+			log.Printf("Error removing playlist entry: %v", err)
+			writeInternalError(w, r, err, "Failed to remove playlist entry")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
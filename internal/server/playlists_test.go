@@ -0,0 +1,65 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+func TestPlaylistHandlers(t *testing.T) {
+	h := newTestHandler(t,
+		&storage.Song{Group: "Muse", Song: "Hole"},
+		&storage.Song{Group: "Kino", Song: "Kukushka"},
+	)
+
+	var playlist storage.Playlist
+	if w := serve(t, h, newRequest(http.MethodPost, "/playlists", `{"name": "Road trip"}`), &playlist); w.Code != http.StatusCreated || playlist.ID != 1 {
+		t.Fatalf("POST /playlists = %d %+v, want the playlist created", w.Code, playlist)
+	}
+	serve(t, h, newRequest(http.MethodPost, "/playlists/1/entries", `{"songId": 1}`), nil)
+	w := serve(t, h, newRequest(http.MethodPost, "/playlists/1/entries", `{"songId": 2, "position": 1}`), &playlist)
+	if w.Code != http.StatusOK || len(playlist.Entries) != 2 || playlist.Entries[0].Song.Song != "Kukushka" {
+		t.Fatalf("POST /playlists/1/entries = %d %+v, want Kukushka first", w.Code, playlist.Entries)
+	}
+
+	w = serve(t, h, newRequest(http.MethodPatch, "/playlists/1/entries/2", `{"position": 2}`), &playlist)
+	if w.Code != http.StatusOK || playlist.Entries[1].Song.Song != "Kukushka" || playlist.Entries[1].Position != 2 {
+		t.Errorf("PATCH /playlists/1/entries/2 = %d %+v, want Kukushka second", w.Code, playlist.Entries)
+	}
+
+	var page storage.PlaylistPage
+	if w := serve(t, h, newRequest(http.MethodGet, "/playlists?name=road", ""), &page); w.Code != http.StatusOK || page.Total != 1 || page.Items[0].Entries != nil {
+		t.Errorf("GET /playlists = %d %+v, want 1 playlist without entries", w.Code, page)
+	}
+
+	tests := []struct {
+		method, target, body string
+		status               int
+		code                 string
+	}{
+		{http.MethodGet, "/playlists/1", "", http.StatusOK, ""},
+		{http.MethodGet, "/playlists/9", "", http.StatusNotFound, codeNotFound},
+		{http.MethodPost, "/playlists", `{"name": ""}`, http.StatusUnprocessableEntity, codeValidation},
+		{http.MethodPut, "/playlists/1", `{"name": "Commute"}`, http.StatusOK, ""},
+		{http.MethodPut, "/playlists/9", `{"name": "Commute"}`, http.StatusNotFound, codeNotFound},
+		{http.MethodPost, "/playlists/1/entries", `{"songId": 9}`, http.StatusUnprocessableEntity, codeValidation},
+		{http.MethodPost, "/playlists/1/entries", `{"songId": 1, "position": 5}`, http.StatusUnprocessableEntity, codeValidation},
+		{http.MethodPost, "/playlists/1/entries", `{"songId": 1, "position": -1}`, http.StatusUnprocessableEntity, codeValidation},
+		{http.MethodPost, "/playlists/9/entries", `{"songId": 1}`, http.StatusNotFound, codeNotFound},
+		{http.MethodPatch, "/playlists/1/entries/1", `{"position": 3}`, http.StatusUnprocessableEntity, codeValidation},
+		{http.MethodPatch, "/playlists/1/entries/9", `{"position": 1}`, http.StatusNotFound, codeNotFound},
+		{http.MethodPatch, "/playlists/1/entries/x", `{"position": 1}`, http.StatusBadRequest, codeInvalidID},
+		{http.MethodDelete, "/playlists/1/entries/1", "", http.StatusNoContent, ""},
+		{http.MethodDelete, "/playlists/1/entries/1", "", http.StatusNotFound, codeNotFound},
+		{http.MethodDelete, "/playlists/1", "", http.StatusNoContent, ""},
+		{http.MethodDelete, "/playlists/1", "", http.StatusNotFound, codeNotFound},
+	}
+	for _, tt := range tests {
+		var p Problem
+		w := serve(t, h, newRequest(tt.method, tt.target, tt.body), &p)
+		if w.Code != tt.status || (tt.code != "" && p.Code != tt.code) {
+			t.Errorf("%s %s %s = %d %s, want %d %s", tt.method, tt.target, tt.body, w.Code, p.Code, tt.status, tt.code)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package server
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/fevse/songlib/internal/app"
+	"github.com/fevse/songlib/internal/storage"
+)
+
+const (
+	defaultLimit = 20
+	// maxLimit caps the page size of every list.
+	maxLimit = 100
+)
+
+var reservedParams = map[string]bool{
+	"limit":   true,
+	"offset":  true,
+	"cursor":  true,
+	"sort":    true,
+	"tag":     true,
+	"tagMode": true,
+}
+
+// releaseRangeParams are shortcuts for release date ranges, releasedAfter
+// is inclusive and releasedBefore is exclusive.
+var releaseRangeParams = map[string]storage.Operator{
+	"releasedAfter":  storage.OpGte,
+	"releasedBefore": storage.OpLt,
+}
+
+// filterParam matches "field" and "field[op]" query keys.
+var filterParam = regexp.MustCompile(`^(\w+)(?:\[(\w+)\])?$`)
+
+// paramError is an invalid value of a query parameter.
+type paramError struct {
+	param string
+	err   error
+}
+
+func (e *paramError) Error() string {
+	return e.param + ": " + e.err.Error()
+}
+
+// parseListQuery reads the filter, tags, sort and pagination of a song
+// list. Errors are *paramError or *storage.FilterError.
+func parseListQuery(query url.Values) (storage.ListQuery, error) {
+	filter, err := parseFilter(query)
+	if err != nil {
+		return storage.ListQuery{}, err
+	}
+
+	sort, err := storage.ParseSort(query.Get("sort"))
+	if err != nil {
+		return storage.ListQuery{}, err
+	}
+
+	q := storage.ListQuery{
+		Filter:  filter,
+		TagMode: storage.TagMode(query.Get("tagMode")),
+		Sort:    sort,
+		Cursor:  query.Get("cursor"),
+	}
+	switch q.TagMode {
+	case "":
+		q.TagMode = storage.TagModeAll
+	case storage.TagModeAll, storage.TagModeAny:
+	default:
+		return storage.ListQuery{}, &paramError{param: "tagMode", err: errors.New("must be all or any")}
+	}
+	for _, tag := range query["tag"] {
+		if tag = storage.TagName(tag); tag != "" && !slices.Contains(q.Tags, tag) {
+			q.Tags = append(q.Tags, tag)
+		}
+	}
+
+	q.Limit = parseLimit(query)
+	if q.Offset, err = parseOffset(query); err != nil {
+		return storage.ListQuery{}, &paramError{param: "offset", err: err}
+	}
+	return q, nil
+}
+
+// parseOffset reads the number of items to skip, 0 when it is missing.
+func parseOffset(query url.Values) (int, error) {
+	value := query.Get("offset")
+	if value == "" {
+		return 0, nil
+	}
+
+	offset, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if offset < 0 {
+		return 0, errors.New("offset must not be negative")
+	}
+	return offset, nil
+}
+
+// parseLimit reads the page size, defaultLimit when it is missing and at
+// most maxLimit.
+func parseLimit(query url.Values) int {
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit <= 0 {
+		return defaultLimit
+	}
+	return min(limit, maxLimit)
+}
+
+func parseFilter(query url.Values) (storage.Filter, error) {
+	var filter storage.Filter
+	for key, values := range query {
+		if reservedParams[key] {
+			continue
+		}
+
+		name, op := key, storage.OpEq
+		if rangeOp, ok := releaseRangeParams[key]; ok {
+			name, op = "releaseDate", rangeOp
+		} else if m := filterParam.FindStringSubmatch(key); m != nil {
+			name = m[1]
+			if m[2] != "" {
+				op = storage.Operator(m[2])
+			}
+		}
+
+		for _, value := range values {
+			if name == "releaseDate" {
+				conds, err := releaseDateConditions(op, value)
+				if err != nil {
+					return nil, &paramError{param: key, err: err}
+				}
+				filter = append(filter, conds...)
+				continue
+			}
+
+			cond, err := storage.NewCondition(name, op, value)
+			if err != nil {
+				return nil, err
+			}
+			filter = append(filter, cond)
+		}
+	}
+	return filter, nil
+}
+
+// releaseDateConditions compares release dates with the whole period of
+// an incomplete date: releaseDate=2006 matches any date in 2006,
+// releaseDate[ne]=2006 any date outside of it and releaseDate[gt]=2006
+// starts from 2007.
+func releaseDateConditions(op storage.Operator, value string) (storage.Filter, error) {
+	date, err := app.ParseReleaseDate(value)
+	if err != nil {
+		return nil, err
+	}
+	start, end := date.String(), date.End().String()
+
+	var conds storage.Filter
+	add := func(op storage.Operator, value string) error {
+		cond, err := storage.NewCondition("releaseDate", op, value)
+		if err != nil {
+			return err
+		}
+		conds = append(conds, cond)
+		return nil
+	}
+
+	switch op {
+	case storage.OpEq:
+		if date.Precision == app.PrecisionDay {
+			err = add(storage.OpEq, start)
+		} else if err = add(storage.OpGte, start); err == nil {
+			err = add(storage.OpLt, end)
+		}
+	case storage.OpNe:
+		if date.Precision == app.PrecisionDay {
+			err = add(storage.OpNe, start)
+		} else if err = add(storage.OpLt, start); err == nil {
+			if err = add(storage.OpGte, end); err == nil {
+				conds = storage.Filter{storage.AnyOf(conds...)}
+			}
+		}
+	case storage.OpGt:
+		err = add(storage.OpGte, end)
+	case storage.OpLte:
+		err = add(storage.OpLt, end)
+	default:
+		err = add(op, start)
+	}
+	return conds, err
+}
+
+// nextPageLink links to the page following the one at offset, it is empty
+// on the last page. cursor is nil for lists paginated by offset only, for
+// the others it points to the cursor of the next page and the link keeps
+// the pagination mode of the request: offset when the client paginates by
+// offset, cursor otherwise.
+func nextPageLink(u *url.URL, offset, limit, total int, cursor *string) string {
+	query := u.Query()
+	if cursor != nil && !query.Has("offset") {
+		if *cursor == "" {
+			return ""
+		}
+		query.Set("cursor", *cursor)
+	} else {
+		next := offset + limit
+		if next >= total {
+			return ""
+		}
+		query.Set("offset", strconv.Itoa(next))
+	}
+	query.Set("limit", strconv.Itoa(limit))
+	return (&url.URL{Path: u.Path, RawQuery: query.Encode()}).String()
+}
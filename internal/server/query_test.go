@@ -0,0 +1,188 @@
+package server
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+func TestParseListQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  storage.ListQuery
+	}{
+		{
+			"",
+			storage.ListQuery{TagMode: storage.TagModeAll, Limit: defaultLimit},
+		},
+		{
+			"group=Muse",
+			storage.ListQuery{
+				Filter:  storage.Filter{{Field: "group", Op: storage.OpEq, Value: "Muse"}},
+				TagMode: storage.TagModeAll, Limit: defaultLimit,
+			},
+		},
+		{
+			"song[contains]=hole&song[contains]=black",
+			storage.ListQuery{
+				Filter: storage.Filter{
+					{Field: "song", Op: storage.OpContains, Value: "hole"},
+					{Field: "song", Op: storage.OpContains, Value: "black"},
+				},
+				TagMode: storage.TagModeAll, Limit: defaultLimit,
+			},
+		},
+		{
+			"tag=Rock&tag=rock&tag=&tagMode=any",
+			storage.ListQuery{Tags: []string{"rock"}, TagMode: storage.TagModeAny, Limit: defaultLimit},
+		},
+		{
+			"sort=-releaseDate,group",
+			storage.ListQuery{
+				TagMode: storage.TagModeAll,
+				Sort:    storage.Sort{{Field: "releaseDate", Desc: true}, {Field: "group"}},
+				Limit:   defaultLimit,
+			},
+		},
+		{
+			"limit=5&offset=10&cursor=abc",
+			storage.ListQuery{TagMode: storage.TagModeAll, Limit: 5, Offset: 10, Cursor: "abc"},
+		},
+		{
+			"limit=0",
+			storage.ListQuery{TagMode: storage.TagModeAll, Limit: defaultLimit},
+		},
+		{
+			"limit=100000",
+			storage.ListQuery{TagMode: storage.TagModeAll, Limit: maxLimit},
+		},
+		{
+			"limit=abc",
+			storage.ListQuery{TagMode: storage.TagModeAll, Limit: defaultLimit},
+		},
+	}
+	for _, tt := range tests {
+		query, _ := url.ParseQuery(tt.query)
+		got, err := parseListQuery(query)
+		if err != nil {
+			t.Errorf("parseListQuery(%q): %v", tt.query, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseListQuery(%q) = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseListQueryErrors(t *testing.T) {
+	tests := []struct {
+		query     string
+		wantParam string
+	}{
+		{"genre=rock", ""},
+		{"group[gt]=M", ""},
+		{"text[like]=love", ""},
+		{"artistId[contains]=1", ""},
+		{"artistId=abc", ""},
+		{"albumId[gt]=1", ""},
+		{"albumId[ne]=9999999999", ""},
+		{"sort=text,-genre", ""},
+		{"tagMode=some", "tagMode"},
+		{"releaseDate=yesterday", "releaseDate"},
+		{"releasedAfter=31.02.2006", "releasedAfter"},
+		{"offset=-3", "offset"},
+		{"offset=x", "offset"},
+	}
+	for _, tt := range tests {
+		query, _ := url.ParseQuery(tt.query)
+		_, err := parseListQuery(query)
+
+		var filterErr *storage.FilterError
+		var paramErr *paramError
+		switch {
+		case tt.wantParam == "" && !errors.As(err, &filterErr):
+			t.Errorf("parseListQuery(%q) err = %v, want *storage.FilterError", tt.query, err)
+		case tt.wantParam != "" && (!errors.As(err, &paramErr) || paramErr.param != tt.wantParam):
+			t.Errorf("parseListQuery(%q) err = %v, want *paramError for %s", tt.query, err, tt.wantParam)
+		}
+	}
+}
+
+func TestReleaseDateConditions(t *testing.T) {
+	cond := func(op storage.Operator, value string) storage.Condition {
+		return storage.Condition{Field: "releaseDate", Op: op, Value: value}
+	}
+	tests := []struct {
+		op    storage.Operator
+		value string
+		want  storage.Filter
+	}{
+		{storage.OpEq, "16.07.2006", storage.Filter{cond(storage.OpEq, "2006-07-16")}},
+		{storage.OpEq, "2006", storage.Filter{cond(storage.OpGte, "2006-01-01"), cond(storage.OpLt, "2007-01-01")}},
+		{storage.OpEq, "07.2006", storage.Filter{cond(storage.OpGte, "2006-07-01"), cond(storage.OpLt, "2006-08-01")}},
+		{storage.OpNe, "2006-07-16", storage.Filter{cond(storage.OpNe, "2006-07-16")}},
+		{storage.OpNe, "2006", storage.Filter{
+			storage.AnyOf(cond(storage.OpLt, "2006-01-01"), cond(storage.OpGte, "2007-01-01")),
+		}},
+		{storage.OpNe, "2006-12", storage.Filter{
+			storage.AnyOf(cond(storage.OpLt, "2006-12-01"), cond(storage.OpGte, "2007-01-01")),
+		}},
+		{storage.OpGt, "2006", storage.Filter{cond(storage.OpGte, "2007-01-01")}},
+		{storage.OpGte, "2006", storage.Filter{cond(storage.OpGte, "2006-01-01")}},
+		{storage.OpLt, "2006", storage.Filter{cond(storage.OpLt, "2006-01-01")}},
+		{storage.OpLte, "2006", storage.Filter{cond(storage.OpLt, "2007-01-01")}},
+		{storage.OpLte, "2006-07-16", storage.Filter{cond(storage.OpLt, "2006-07-17")}},
+	}
+	for _, tt := range tests {
+		got, err := releaseDateConditions(tt.op, tt.value)
+		if err != nil {
+			t.Errorf("releaseDateConditions(%s, %q): %v", tt.op, tt.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("releaseDateConditions(%s, %q) = %+v, want %+v", tt.op, tt.value, got, tt.want)
+		}
+	}
+
+	if _, err := releaseDateConditions(storage.OpContains, "2006"); err == nil {
+		t.Error("releaseDateConditions(contains) succeeded, want an error")
+	}
+}
+
+func TestReleaseRangeParams(t *testing.T) {
+	query, _ := url.ParseQuery("releasedBefore=2006")
+	got, err := parseListQuery(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := storage.Filter{{Field: "releaseDate", Op: storage.OpLt, Value: "2006-01-01"}}
+	if !reflect.DeepEqual(got.Filter, want) {
+		t.Errorf("releasedBefore=2006 filter = %+v, want %+v", got.Filter, want)
+	}
+}
+
+func TestNextPageLink(t *testing.T) {
+	cursor, noCursor := "abc", ""
+	tests := []struct {
+		target string
+		offset int
+		cursor *string
+		want   string
+	}{
+		{"/albums", 0, nil, "/albums?limit=2&offset=2"},
+		{"/albums?offset=2&q=x", 2, nil, "/albums?limit=2&offset=4&q=x"},
+		{"/albums?offset=4", 4, nil, ""},
+		{"/songs", 0, &cursor, "/songs?cursor=abc&limit=2"},
+		{"/songs?cursor=xyz", 0, &noCursor, ""},
+		{"/songs?offset=2", 2, &cursor, "/songs?limit=2&offset=4"},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.target)
+		if got := nextPageLink(u, tt.offset, 2, 5, tt.cursor); got != tt.want {
+			t.Errorf("nextPageLink(%s) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+// SearchSongs godoc
+// @Summary Полнотекстовый поиск песен
+// @Description Поиск по названию, исполнителю и тексту песни с учетом словоформ русского и английского языков.
+// @Description q - запрос: слова, "фраза в кавычках", or между вариантами, -слово исключает песни с ним.
+// @Description Исполнитель и название находятся и в транслитерации: "Kino Gruppa krovi" найдет "Кино - Группа крови".
+// @Description Результаты упорядочены по релевантности, snippet - фрагмент текста с найденными словами в <b>.
+// @Description Если ничего не найдено, suggestions содержит похожие названия и исполнителей ("возможно, вы имели в виду")
+// @Tags songs
+// @Produce  json
+// @Param q query string true "Search query"
+// @Param limit query int false "Limit the number of results, at most 100"
+// @Param offset query int false "Offset for pagination"
+// @Success 200 {object} storage.SearchPage
+// @Header 200 {integer} X-Total-Count "Total number of matching songs"
+// @Header 200 {string} Link "Link to the next page"
+// @Failure 400 {object} Problem "Missing q or invalid offset"
+// @Failure 500 {object} Problem "Failed to search songs"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /songs/search [get]
+func (s *Server) SearchSongs() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if query == "" {
+			writeParamError(w, r, "q", errors.New("is required"))
+			return
+		}
+
+		limit := parseLimit(r.URL.Query())
+		offset, err := parseOffset(r.URL.Query())
+		if err != nil {
+			log.Printf("Error parsing offset: %v", err)
+			writeParamError(w, r, "offset", err)
+			return
+		}
+
+		page, err := s.app.SearchSongs(r.Context(), storage.SearchQuery{
+			Query:  query,
+			Limit:  limit,
+			Offset: offset,
+		})
+		if err != nil {
+			log.Printf("Error searching songs: %v", err)
+			writeInternalError(w, r, err, "Failed to search songs")
+			return
+		}
+
+		page.Next = nextPageLink(r.URL, page.Offset, page.Limit, page.Total, nil)
+		if page.Next != "" {
+			w.Header().Set("Link", "<"+page.Next+`>; rel="next"`)
+		}
+		w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(page)
+	}
+}
+
+// FuzzySongs godoc
+// @Summary Нечеткий поиск по названию и исполнителю
+// @Description Находит песни, у которых исполнитель, название или оба вместе похожи на q, даже с опечатками ("Muze", "Supermassive Blackhole").
+// @Description Кириллица и латиница сравниваются после транслитерации, "Tsoi" похоже на "Цой".
+// @Description score - сходство по триграммам от 0 до 1, match - самое похожее из исполнителя, названия или их сочетания.
+// @Description minScore - порог сходства, найденные песни похожи больше него, по умолчанию 0.2
+// @Tags songs
+// @Produce  json
+// @Param q query string true "Band, title or both"
+// @Param minScore query number false "Similarity a match must exceed, from 0 to 1" default(0.2)
+// @Param limit query int false "Limit the number of results, at most 100"
+// @Success 200 {object} storage.FuzzyPage
+// @Failure 400 {object} Problem "Missing q or invalid minScore"
+// @Failure 500 {object} Problem "Failed to match songs"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /songs/fuzzy [get]
+func (s *Server) FuzzySongs() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if query == "" {
+			writeParamError(w, r, "q", errors.New("is required"))
+			return
+		}
+
+		minScore := storage.DefaultMinScore
+		if v := r.URL.Query().Get("minScore"); v != "" {
+			var err error
+			minScore, err = strconv.ParseFloat(v, 64)
+			if err != nil || minScore <= 0 || minScore > 1 {
+				writeParamError(w, r, "minScore", errors.New("must be a number greater than 0 and at most 1"))
+				return
+			}
+		}
+
+		limit := parseLimit(r.URL.Query())
+
+		page, err := s.app.FuzzySongs(r.Context(), storage.FuzzyQuery{
+			Query:    query,
+			MinScore: minScore,
+			Limit:    limit,
+		})
+		if err != nil {
+			log.Printf("Error matching songs: %v", err)
+			writeInternalError(w, r, err, "Failed to match songs")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(page)
+	}
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+func TestSearchSongs(t *testing.T) {
+	h := newTestHandler(t,
+		&storage.Song{Group: "Muse", Song: "Hole", Text: "a hole in the sky"},
+		&storage.Song{Group: "Black Holes", Song: "Starlight"},
+		&storage.Song{Group: "Kino", Song: "Kukushka"},
+	)
+
+	var page storage.SearchPage
+	w := serve(t, h, newRequest(http.MethodGet, "/songs/search?q=hole&limit=1", ""), &page)
+	if w.Code != http.StatusOK || page.Total != 2 || len(page.Items) != 1 || page.Items[0].ID != 1 {
+		t.Fatalf("GET /songs/search = %d %+v, want the first of 2 songs", w.Code, page)
+	}
+	if page.Items[0].Rank <= 0 || page.Items[0].Snippet != "a <b>hole</b> in the sky" {
+		t.Errorf("result = %+v, want a rank and a snippet", page.Items[0])
+	}
+	if want := "/songs/search?limit=1&offset=1&q=hole"; page.Next != want || w.Header().Get("X-Total-Count") != "2" {
+		t.Errorf("next = %q, X-Total-Count %q, want %q and 2", page.Next, w.Header().Get("X-Total-Count"), want)
+	}
+
+	var p Problem
+	if w := serve(t, h, newRequest(http.MethodGet, "/songs/search?q=+", ""), &p); w.Code != http.StatusBadRequest || p.Code != codeInvalidParameter {
+		t.Errorf("GET without q = %d %s, want %d %s", w.Code, p.Code, http.StatusBadRequest, codeInvalidParameter)
+	}
+}
+
+func TestSearchSuggestions(t *testing.T) {
+	h := newTestHandler(t,
+		&storage.Song{Group: "Muse", Song: "Hole"},
+		&storage.Song{Group: "Muse", Song: "Uprising"},
+	)
+
+	var page storage.SearchPage
+	w := serve(t, h, newRequest(http.MethodGet, "/songs/search?q=muze", ""), &page)
+	if w.Code != http.StatusOK || page.Total != 0 || !reflect.DeepEqual(page.Suggestions, []string{"Muse"}) {
+		t.Errorf("GET /songs/search?q=muze = %d %+v, want the suggestion Muse", w.Code, page)
+	}
+	page = storage.SearchPage{}
+	serve(t, h, newRequest(http.MethodGet, "/songs/search?q=muse", ""), &page)
+	if page.Total != 2 || page.Suggestions != nil {
+		t.Errorf("GET /songs/search?q=muse = %+v, want 2 songs without suggestions", page)
+	}
+}
+
+func TestFuzzySongs(t *testing.T) {
+	h := newTestHandler(t,
+		&storage.Song{Group: "Muse", Song: "Supermassive Black Hole"},
+		&storage.Song{Group: "Kino", Song: "Kukushka"},
+	)
+
+	var page storage.FuzzyPage
+	w := serve(t, h, newRequest(http.MethodGet, "/songs/fuzzy?q=Supermassive+Blackhole", ""), &page)
+	if w.Code != http.StatusOK || len(page.Items) != 1 || page.Items[0].ID != 1 || page.Items[0].Match != "Supermassive Black Hole" {
+		t.Fatalf("GET /songs/fuzzy = %d %+v, want the song of Muse", w.Code, page)
+	}
+	if page.Items[0].Score <= 0 || page.Items[0].Score > 1 {
+		t.Errorf("score = %v, want within (0, 1]", page.Items[0].Score)
+	}
+	page = storage.FuzzyPage{}
+	if serve(t, h, newRequest(http.MethodGet, "/songs/fuzzy?q=Muze&minScore=0.9", ""), &page); len(page.Items) != 0 {
+		t.Errorf("GET with minScore 0.9 = %+v, want no matches", page)
+	}
+
+	for _, target := range []string{"/songs/fuzzy", "/songs/fuzzy?q=Muse&minScore=0", "/songs/fuzzy?q=Muse&minScore=1.5", "/songs/fuzzy?q=Muse&minScore=x"} {
+		var p Problem
+		if w := serve(t, h, newRequest(http.MethodGet, target, ""), &p); w.Code != http.StatusBadRequest || p.Code != codeInvalidParameter {
+			t.Errorf("GET %s = %d %s, want %d %s", target, w.Code, p.Code, http.StatusBadRequest, codeInvalidParameter)
+		}
+	}
+}
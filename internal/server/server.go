@@ -4,6 +4,7 @@ import (
 	"context"
 	"net"
 	"net/http"
+	"time"
 
 	_ "github.com/fevse/songlib/docs"
 	"github.com/fevse/songlib/internal/app"
@@ -11,28 +12,30 @@ import (
 )
 
 type Server struct {
-	server *http.Server
-	app    *app.SongLibApp
+	server         *http.Server
+	app            *app.SongLibApp
+	requestTimeout time.Duration
+	idempotencyTTL time.Duration
+	// cancelRequests cancels the requests in flight when they do not
+	// finish within the shutdown timeout.
+	cancelRequests context.CancelFunc
 }
 
-func NewServer(app *app.SongLibApp, host, port string) *Server {
+func NewServer(app *app.SongLibApp, host, port string, requestTimeout, idempotencyTTL time.Duration) *Server {
+	base, cancel := context.WithCancel(context.Background())
 	return &Server{
 		server: &http.Server{
-			Addr: net.JoinHostPort(host, port),
+			Addr:        net.JoinHostPort(host, port),
+			BaseContext: func(net.Listener) context.Context { return base },
 		},
-		app: app}
+		app:            app,
+		requestTimeout: requestTimeout,
+		idempotencyTTL: idempotencyTTL,
+		cancelRequests: cancel}
 }
 
 func (s *Server) Start(ctx context.Context) error {
-	mux := http.NewServeMux()
-
-	mux.Handle("POST /songs", s.CreateSong())
-	mux.Handle("GET /songs", s.GetSongs())
-	mux.Handle("PUT /songs/{id}", s.UpdateSong())
-	mux.Handle("DELETE /songs/{id}", s.DeleteSong())
-	mux.Handle("/swagger/", httpSwagger.WrapHandler)
-
-	s.server.Handler = mux
+	s.server.Handler = s.routes()
 	err := s.server.ListenAndServe()
 	if err == http.ErrServerClosed {
 		return nil
@@ -45,6 +48,52 @@ func (s *Server) Start(ctx context.Context) error {
 
 }
 
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.Handle("POST /songs", s.idempotent(s.CreateSong()))
+	mux.Handle("GET /songs", s.GetSongs())
+	mux.Handle("GET /songs/facets", s.GetSongFacets())
+	mux.Handle("GET /songs/search", s.SearchSongs())
+	mux.Handle("GET /songs/fuzzy", s.FuzzySongs())
+	mux.Handle("GET /songs/duplicates", s.GetDuplicates())
+	mux.Handle("GET /songs/{id}", s.GetSong())
+	mux.Handle("GET /songs/{id}/text", s.GetSongText())
+	mux.Handle("PUT /songs/{id}", s.UpdateSong())
+	mux.Handle("PATCH /songs/{id}", s.PatchSong())
+	mux.Handle("DELETE /songs/{id}", s.DeleteSong())
+	mux.Handle("POST /songs/{id}/tags", s.AddSongTags())
+	mux.Handle("DELETE /songs/{id}/tags", s.RemoveSongTags())
+	mux.Handle("POST /songs/{id}/merge", s.MergeSong())
+	mux.Handle("POST /artists", s.CreateArtist())
+	mux.Handle("GET /artists", s.GetArtists())
+	mux.Handle("GET /artists/{id}", s.GetArtist())
+	mux.Handle("GET /artists/{id}/songs", s.GetArtistSongs())
+	mux.Handle("PUT /artists/{id}", s.UpdateArtist())
+	mux.Handle("DELETE /artists/{id}", s.DeleteArtist())
+	mux.Handle("POST /albums", s.CreateAlbum())
+	mux.Handle("GET /albums", s.GetAlbums())
+	mux.Handle("GET /albums/{id}", s.GetAlbum())
+	mux.Handle("PUT /albums/{id}", s.UpdateAlbum())
+	mux.Handle("DELETE /albums/{id}", s.DeleteAlbum())
+	mux.Handle("PUT /albums/{id}/tracks", s.SetAlbumTracks())
+	mux.Handle("DELETE /albums/{id}/tracks/{songId}", s.RemoveAlbumTrack())
+	mux.Handle("POST /playlists", s.CreatePlaylist())
+	mux.Handle("GET /playlists", s.GetPlaylists())
+	mux.Handle("GET /playlists/{id}", s.GetPlaylist())
+	mux.Handle("PUT /playlists/{id}", s.UpdatePlaylist())
+	mux.Handle("DELETE /playlists/{id}", s.DeletePlaylist())
+	mux.Handle("POST /playlists/{id}/entries", s.InsertPlaylistEntry())
+	mux.Handle("PATCH /playlists/{id}/entries/{entryId}", s.MovePlaylistEntry())
+	mux.Handle("DELETE /playlists/{id}/entries/{entryId}", s.RemovePlaylistEntry())
+	mux.Handle("/swagger/", httpSwagger.WrapHandler)
+
+	return withRequestID(withTimeout(s.requestTimeout, mux))
+}
+
+// Stop waits for the requests in flight to finish until ctx is done, then
+// cancels those still running.
 func (s *Server) Stop(ctx context.Context) error {
+	defer s.cancelRequests()
 	return s.server.Shutdown(ctx)
 }
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// serveBlocking serves a handler that reports the error of its request
+// context when it is done or after wait, whichever comes first. It
+// returns once the request is in flight.
+func serveBlocking(t *testing.T, s *Server, wait time.Duration) <-chan error {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan struct{})
+	done := make(chan error, 1)
+	s.server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		select {
+		case <-r.Context().Done():
+			done <- r.Context().Err()
+		case <-time.After(wait):
+			done <- nil
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	go s.server.Serve(ln)
+	go func() {
+		if resp, err := http.Get("http://" + ln.Addr().String()); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+	return done
+}
+
+func TestStopWaitsForRequests(t *testing.T) {
+	s := NewServer(nil, "", "", 0, time.Hour)
+	done := serveBlocking(t, s, 50*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("request in flight err = %v, want it finished", err)
+	}
+}
+
+func TestStopCancelsRequests(t *testing.T) {
+	s := NewServer(nil, "", "", 0, time.Hour)
+	done := serveBlocking(t, s, 5*time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := s.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop err = %v, want context.DeadlineExceeded", err)
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("request in flight err = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("request in flight was not cancelled after the shutdown timeout")
+	}
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+// AddSongTags godoc
+// @Summary Добавление меток песне
+// @Description Добавляет песне метки, новые метки создаются. Имена меток не зависят от регистра и пробелов,
+// @Description kind - необязательный тип метки (genre, mood, decade), у существующей метки задается только если его не было
+// @Tags songs
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Song ID"
+// @Param tags body []storage.Tag true "Tags to add"
+// @Success 200 {object} storage.Song
+// @Failure 400 {object} Problem "Invalid ID or JSON"
+// @Failure 404 {object} Problem "Song not found"
+// @Failure 422 {object} Problem "Validation failed"
+// @Failure 500 {object} Problem "Failed to add tags"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /songs/{id}/tags [post]
+func (s *Server) AddSongTags() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Printf("Error converting id to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
+			return
+		}
+
+		var tags []storage.Tag
+		if err := json.NewDecoder(r.Body).Decode(&tags); err != nil {
+			log.Printf("Error decoding JSON: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidJSON, "Invalid JSON")
+			return
+		}
+
+		song, err := s.app.AddTags(r.Context(), id, tags)
+		if handleWriteError(w, r, err, "Song") {
+			return
+		} else if err != nil {
+			log.Printf("Error adding song tags: %v", err)
+			writeInternalError(w, r, err, "Failed to add tags")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(song)
+	}
+}
+
+// RemoveSongTags godoc
+// @Summary Удаление меток песни
+// @Description Убирает у песни метки, переданные в параметрах tag. Метки, которых у песни нет, пропускаются
+// @Tags songs
+// @Produce  json
+// @Param id path int true "Song ID"
+// @Param tag query []string true "Tags to remove" collectionFormat(multi)
+// @Success 200 {object} storage.Song
+// @Failure 400 {object} Problem "Invalid ID or missing tag"
+// @Failure 404 {object} Problem "Song not found"
+// @Failure 500 {object} Problem "Failed to remove tags"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /songs/{id}/tags [delete]
+func (s *Server) RemoveSongTags() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Printf("Error converting id to int: %v", err)
+			writeError(w, r, http.StatusBadRequest, codeInvalidID, "Invalid ID")
+			return
+		}
+
+		names := r.URL.Query()["tag"]
+		if len(names) == 0 {
+			writeParamError(w, r, "tag", errors.New("at least one tag is required"))
+			return
+		}
+
+		song, err := s.app.RemoveTags(r.Context(), id, names)
+		if errors.Is(err, storage.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Song not found")
+			return
+		} else if err != nil {
+			log.Printf("Error removing song tags: %v", err)
+			writeInternalError(w, r, err, "Failed to remove tags")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(song)
+	}
+}
+
+// GetSongFacets godoc
+// @Summary Количество песен по меткам
+// @Description Для песен, подходящих под фильтры GET /songs, возвращает их общее число и количество песен с каждой меткой,
+// @Description начиная с самых частых. kind - учитывать только метки этого типа. Сортировка и пагинация не используются
+// @Tags songs
+// @Produce  json
+// @Param kind query string false "Count only tags of the kind"
+// @Param tag query []string false "Filter by tags" collectionFormat(multi)
+// @Param tagMode query string false "Match all or any of the tags" Enums(all, any)
+// @Success 200 {object} storage.Facets
+// @Failure 400 {object} Problem "Unknown filter field, invalid tagMode"
+// @Failure 500 {object} Problem "Failed to get facets"
+// @Failure 504 {object} Problem "Request timed out"
+// @Router /songs/facets [get]
+func (s *Server) GetSongFacets() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		kind := query.Get("kind")
+		query.Del("kind")
+
+		q, err := parseListQuery(query)
+		if err != nil {
+			log.Printf("Error parsing query: %v", err)
+			writeQueryError(w, r, err)
+			return
+		}
+
+		facets, err := s.app.GetFacets(r.Context(), q, kind)
+		if err != nil {
+			log.Printf("Error getting facets: %v", err)
+			writeInternalError(w, r, err, "Failed to get facets")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(facets)
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/fevse/songlib/internal/storage"
+)
+
+func TestTagHandlers(t *testing.T) {
+	h := newTestHandler(t,
+		&storage.Song{Group: "Muse", Song: "Hole"},
+		&storage.Song{Group: "Kino", Song: "Kukushka"},
+	)
+
+	var song storage.Song
+	w := serve(t, h, newRequest(http.MethodPost, "/songs/1/tags", `[{"name": "Rock", "kind": "genre"}, {"name": "live"}]`), &song)
+	if w.Code != http.StatusOK || !reflect.DeepEqual(song.Tags, []string{"live", "rock"}) {
+		t.Errorf("POST /songs/1/tags = %d %q, want [live rock]", w.Code, song.Tags)
+	}
+	serve(t, h, newRequest(http.MethodPost, "/songs/2/tags", `[{"name": "rock"}]`), nil)
+
+	var page storage.SongPage
+	if w := serve(t, h, newRequest(http.MethodGet, "/songs?tag=rock&tag=live&tagMode=any", ""), &page); w.Code != http.StatusOK || page.Total != 2 {
+		t.Errorf("GET /songs with any of 2 tags = %d %+v, want 2 songs", w.Code, page)
+	}
+	if w := serve(t, h, newRequest(http.MethodGet, "/songs?tag=rock&tag=live", ""), &page); w.Code != http.StatusOK || page.Total != 1 {
+		t.Errorf("GET /songs with all of 2 tags = %d %+v, want 1 song", w.Code, page)
+	}
+
+	var facets storage.Facets
+	w = serve(t, h, newRequest(http.MethodGet, "/songs/facets?kind=genre", ""), &facets)
+	want := storage.Facets{Total: 2, Tags: []storage.TagCount{{Name: "rock", Kind: "genre", Count: 2}}}
+	if w.Code != http.StatusOK || !reflect.DeepEqual(facets, want) {
+		t.Errorf("GET /songs/facets = %d %+v, want %+v", w.Code, facets, want)
+	}
+
+	w = serve(t, h, newRequest(http.MethodDelete, "/songs/1/tags?tag=ROCK", ""), &song)
+	if w.Code != http.StatusOK || !reflect.DeepEqual(song.Tags, []string{"live"}) {
+		t.Errorf("DELETE /songs/1/tags = %d %q, want [live]", w.Code, song.Tags)
+	}
+
+	tests := []struct {
+		method, target, body string
+		status               int
+		code                 string
+	}{
+		{http.MethodPost, "/songs/9/tags", `[{"name": "rock"}]`, http.StatusNotFound, codeNotFound},
+		{http.MethodPost, "/songs/1/tags", `[]`, http.StatusUnprocessableEntity, codeValidation},
+		{http.MethodPost, "/songs/1/tags", `[{"name": " "}]`, http.StatusUnprocessableEntity, codeValidation},
+		{http.MethodPost, "/songs/1/tags", `{"name": "rock"}`, http.StatusBadRequest, codeInvalidJSON},
+		{http.MethodDelete, "/songs/1/tags", "", http.StatusBadRequest, codeInvalidParameter},
+		{http.MethodDelete, "/songs/9/tags?tag=rock", "", http.StatusNotFound, codeNotFound},
+		{http.MethodGet, "/songs/facets?tagMode=some", "", http.StatusBadRequest, codeInvalidParameter},
+		{http.MethodGet, "/songs/facets?genre=rock", "", http.StatusBadRequest, codeInvalidFilter},
+	}
+	for _, tt := range tests {
+		var p Problem
+		w := serve(t, h, newRequest(tt.method, tt.target, tt.body), &p)
+		if w.Code != tt.status || p.Code != tt.code {
+			t.Errorf("%s %s %s = %d %s, want %d %s", tt.method, tt.target, tt.body, w.Code, p.Code, tt.status, tt.code)
+		}
+	}
+}
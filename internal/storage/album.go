@@ -0,0 +1,271 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+)
+
+// ErrUnknownSong is returned when a track refers to a song that does not
+// exist.
+var ErrUnknownSong = errors.New("unknown song")
+
+const albumColumns = `albums.id, albums.title, albums.artist_id, artists.name,
+	albums.release_date, COALESCE(albums.release_date_precision, '')`
+
+const albumsFrom = ` FROM albums JOIN artists ON artists.id = albums.artist_id`
+
+func scanAlbum(row scanner, album *Album) error {
+	return row.Scan(&album.ID, &album.Title, &album.ArtistID, &album.Artist,
+		dateColumn{&album.ReleaseDate}, &album.ReleaseDatePrecision)
+}
+
+// albumArtist checks that the artist of the album exists and fills in
+// its name.
+func albumArtist(ctx context.Context, c conn, album *Album) error {
+	artist, err := getArtist(ctx, c, `id = $1`, album.ArtistID)
+	if errors.Is(err, ErrNotFound) {
+		return ErrUnknownArtist
+	} else if err != nil {
+		return err
+	}
+	album.Artist = artist.Name
+	return nil
+}
+
+func (r *Storage) CreateAlbum(ctx context.Context, album *Album) error {
+	c := r.conn()
+	if err := albumArtist(ctx, c, album); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO albums (title, artist_id, release_date, release_date_precision)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`
+	err := c.QueryRow(ctx, query, album.Title, album.ArtistID,
+		nullString(album.ReleaseDate), nullString(album.ReleaseDatePrecision)).Scan(&album.ID)
+	if err != nil {
+		log.Printf("Error creating album: %v", err)
+		return mapError(err)
+	}
+	return nil
+}
+
+// GetAlbum returns the album with its tracks.
+func (r *Storage) GetAlbum(ctx context.Context, id int) (*Album, error) {
+	c := r.conn()
+	album, err := getAlbum(ctx, c, id, "")
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + songColumns + ` FROM songs WHERE album_id = $1 ORDER BY disc_number, track_number, id`
+	rows, err := c.Query(ctx, query, id)
+	if err != nil {
+		log.Printf("Error getting album tracks: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	album.Tracks = []Song{}
+	for rows.Next() {
+		var song Song
+		if err := scanSong(rows, &song); err != nil {
+			log.Printf("Error scanning song: %v", err)
+			return nil, err
+		}
+		album.Tracks = append(album.Tracks, song)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error getting album tracks: %v", err)
+		return nil, err
+	}
+	if err := loadTags(ctx, c, songPointers(album.Tracks)...); err != nil {
+		return nil, err
+	}
+	return album, nil
+}
+
+func getAlbum(ctx context.Context, c conn, id int, lock string) (*Album, error) {
+	query := `SELECT ` + albumColumns + albumsFrom + ` WHERE albums.id = $1`
+	if lock != "" {
+		query += lock + ` OF albums`
+	}
+
+	var album Album
+	err := scanAlbum(c.QueryRow(ctx, query, id), &album)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		log.Printf("Error getting album: %v", err)
+		return nil, err
+	}
+	return &album, nil
+}
+
+func (r *Storage) UpdateAlbum(ctx context.Context, album *Album) error {
+	c := r.conn()
+	if err := albumArtist(ctx, c, album); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE albums
+		SET title = $1, artist_id = $2, release_date = $3, release_date_precision = $4
+		WHERE id = $5`
+	res, err := c.Exec(ctx, query, album.Title, album.ArtistID,
+		nullString(album.ReleaseDate), nullString(album.ReleaseDatePrecision), album.ID)
+	if err != nil {
+		log.Printf("Error updating album: %v", err)
+		return mapError(err)
+	}
+	return checkAffected(res)
+}
+
+// DeleteAlbum deletes the album, its songs stay in the library without
+// an album.
+func (r *Storage) DeleteAlbum(ctx context.Context, id int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	c := conn{q: tx, dialect: &r.dialect}
+	if err := clearTracks(ctx, c, id); err != nil {
+		return err
+	}
+
+	res, err := c.Exec(ctx, `DELETE FROM albums WHERE id = $1`, id)
+	if err != nil {
+		log.Printf("Error deleting album: %v", err)
+		return err
+	}
+	if err := checkAffected(res); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return err
+	}
+	return nil
+}
+
+func clearTracks(ctx context.Context, c conn, albumID int) error {
+	query := `UPDATE songs SET album_id = NULL, disc_number = NULL, track_number = NULL WHERE album_id = $1`
+	if _, err := c.Exec(ctx, query, albumID); err != nil {
+		log.Printf("Error clearing album tracks: %v", err)
+		return err
+	}
+	return nil
+}
+
+// SetTracks replaces the track listing of the album. Songs are moved
+// from the albums they were on, songs without a release date get the
+// date of the album.
+func (r *Storage) SetTracks(ctx context.Context, albumID int, tracks []Track) (*Album, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	c := conn{q: tx, dialect: &r.dialect}
+	album, err := getAlbum(ctx, c, albumID, r.dialect.lock)
+	if err != nil {
+		return nil, err
+	}
+	if err := clearTracks(ctx, c, albumID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE songs
+		SET album_id = $1, disc_number = $2, track_number = $3,
+			release_date = COALESCE(release_date, $4),
+			release_date_precision = CASE WHEN release_date IS NULL THEN $5 ELSE release_date_precision END
+		WHERE id = $6`
+	for _, track := range tracks {
+		res, err := c.Exec(ctx, query, albumID, track.DiscNumber, track.TrackNumber,
+			nullString(album.ReleaseDate), nullString(album.ReleaseDatePrecision), track.SongID)
+		if err != nil {
+			log.Printf("Error setting album track: %v", err)
+			return nil, mapError(err)
+		}
+		if err := checkAffected(res); errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("%w: %d", ErrUnknownSong, track.SongID)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return nil, err
+	}
+	return r.GetAlbum(ctx, albumID)
+}
+
+// RemoveTrack takes the song off the album.
+func (r *Storage) RemoveTrack(ctx context.Context, albumID, songID int) error {
+	query := `
+		UPDATE songs SET album_id = NULL, disc_number = NULL, track_number = NULL
+		WHERE id = $1 AND album_id = $2`
+	res, err := r.conn().Exec(ctx, query, songID, albumID)
+	if err != nil {
+		log.Printf("Error removing album track: %v", err)
+		return err
+	}
+	return checkAffected(res)
+}
+
+func (r *Storage) GetAlbums(ctx context.Context, q AlbumQuery) (*AlbumPage, error) {
+	where, args := " WHERE 1=1", []any{}
+	if q.ArtistID != 0 {
+		args = append(args, q.ArtistID)
+		where += " AND albums.artist_id = $" + strconv.Itoa(len(args))
+	}
+	if q.Title != "" {
+		args = append(args, "%"+escapeLike(q.Title)+"%")
+		where += " AND albums.title " + r.dialect.like + " $" + strconv.Itoa(len(args)) + ` ESCAPE '\'`
+	}
+
+	page := &AlbumPage{Items: []Album{}, Limit: q.Limit, Offset: q.Offset}
+	if err := r.conn().QueryRow(ctx, `SELECT count(*)`+albumsFrom+where, args...).Scan(&page.Total); err != nil {
+		log.Printf("Error counting albums: %v", err)
+		return nil, err
+	}
+
+	args = append(args, q.Limit, q.Offset)
+	query := `SELECT ` + albumColumns + albumsFrom + where +
+		` ORDER BY artists.name_key, COALESCE(albums.release_date, '0001-01-01'), albums.id` +
+		` LIMIT $` + strconv.Itoa(len(args)-1) + ` OFFSET $` + strconv.Itoa(len(args))
+
+	rows, err := r.conn().Query(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error getting albums: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var album Album
+		if err := scanAlbum(rows, &album); err != nil {
+			log.Printf("Error scanning album: %v", err)
+			return nil, err
+		}
+		page.Items = append(page.Items, album)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error getting albums: %v", err)
+		return nil, err
+	}
+	return page, nil
+}
@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAlbums(t *testing.T) {
+	forEachRepository(t, testAlbums)
+}
+
+func testAlbums(t *testing.T, newRepo func(...*Song) Repository) {
+	ctx := context.Background()
+	repo := newRepo(
+		&Song{Group: "Muse", Song: "Knights of Cydonia"},
+		&Song{Group: "Muse", Song: "Starlight", ReleaseDate: "2006-09-04"},
+		&Song{Group: "Muse", Song: "Take a Bow"},
+	)
+
+	if err := repo.CreateAlbum(ctx, &Album{Title: "Black Holes", ArtistID: 9}); !errors.Is(err, ErrUnknownArtist) {
+		t.Errorf("CreateAlbum of a missing artist err = %v, want ErrUnknownArtist", err)
+	}
+	album := &Album{Title: "Black Holes and Revelations", ArtistID: 1, ReleaseDate: "2006-07-03"}
+	if err := repo.CreateAlbum(ctx, album); err != nil {
+		t.Fatal(err)
+	}
+	if album.Artist != "Muse" {
+		t.Errorf("album artist = %q, want Muse", album.Artist)
+	}
+
+	tracks := []Track{
+		{SongID: 3, DiscNumber: 1, TrackNumber: 1},
+		{SongID: 1, DiscNumber: 1, TrackNumber: 11},
+		{SongID: 2, DiscNumber: 1, TrackNumber: 2},
+	}
+	if _, err := repo.SetTracks(ctx, album.ID, append(tracks, Track{SongID: 9})); !errors.Is(err, ErrUnknownSong) {
+		t.Errorf("SetTracks with a missing song err = %v, want ErrUnknownSong", err)
+	}
+	if _, err := repo.SetTracks(ctx, 9, tracks); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SetTracks of a missing album err = %v, want ErrNotFound", err)
+	}
+	got, err := repo.SetTracks(ctx, album.ID, tracks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := songIDs(got.Tracks); !reflect.DeepEqual(ids, []int{3, 2, 1}) {
+		t.Errorf("tracks = %v, want [3 2 1]", ids)
+	}
+	// Tracks without a date take the date of the album.
+	for _, song := range got.Tracks {
+		want := "2006-07-03"
+		if song.ID == 2 {
+			want = "2006-09-04"
+		}
+		if song.AlbumID != album.ID || song.ReleaseDate != want {
+			t.Errorf("track %d = album %d, date %q, want album %d, date %q", song.ID, song.AlbumID, song.ReleaseDate, album.ID, want)
+		}
+	}
+
+	page, err := repo.GetList(ctx, ListQuery{Filter: Filter{{Field: "albumId", Op: OpEq, Value: "1"}}, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := songIDs(page.Items); !reflect.DeepEqual(ids, []int{1, 2, 3}) {
+		t.Errorf("songs of album 1 = %v, want [1 2 3]", ids)
+	}
+
+	// A new listing replaces the old one.
+	if got, err = repo.SetTracks(ctx, album.ID, tracks[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if ids := songIDs(got.Tracks); !reflect.DeepEqual(ids, []int{2, 1}) {
+		t.Errorf("tracks after replacing = %v, want [2 1]", ids)
+	}
+	if song, _ := repo.GetByID(ctx, 3); song.AlbumID != 0 || song.TrackNumber != 0 {
+		t.Errorf("song left out of the album = album %d, track %d, want none", song.AlbumID, song.TrackNumber)
+	}
+	// Like NULL in SQL, a song without an album matches no album condition.
+	page, err = repo.GetList(ctx, ListQuery{Filter: Filter{{Field: "albumId", Op: OpNe, Value: "9"}}, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := songIDs(page.Items); !reflect.DeepEqual(ids, []int{1, 2}) {
+		t.Errorf("songs not of album 9 = %v, want [1 2]", ids)
+	}
+
+	if err := repo.RemoveTrack(ctx, album.ID, 3); !errors.Is(err, ErrNotFound) {
+		t.Errorf("RemoveTrack of a song not on the album err = %v, want ErrNotFound", err)
+	}
+	if err := repo.RemoveTrack(ctx, album.ID, 2); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := repo.GetAlbum(ctx, album.ID); !reflect.DeepEqual(songIDs(got.Tracks), []int{1}) {
+		t.Errorf("tracks after RemoveTrack = %v, want [1]", songIDs(got.Tracks))
+	}
+
+	if err := repo.DeleteAlbum(ctx, album.ID); err != nil {
+		t.Fatal(err)
+	}
+	if song, _ := repo.GetByID(ctx, 1); song.AlbumID != 0 {
+		t.Errorf("song of a deleted album has album %d", song.AlbumID)
+	}
+	if err := repo.DeleteAlbum(ctx, album.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second DeleteAlbum err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestGetAlbums(t *testing.T) {
+	forEachRepository(t, testGetAlbums)
+}
+
+func testGetAlbums(t *testing.T, newRepo func(...*Song) Repository) {
+	ctx := context.Background()
+	repo := newRepo(
+		&Song{Group: "Muse", Song: "Hole"},
+		&Song{Group: "Kino", Song: "Kukushka"},
+	)
+	for _, album := range []*Album{
+		{Title: "Black Holes and Revelations", ArtistID: 1, ReleaseDate: "2006-07-03"},
+		{Title: "Gruppa krovi", ArtistID: 2, ReleaseDate: "1988-01-01"},
+		{Title: "Absolution", ArtistID: 1, ReleaseDate: "2003-09-15"},
+	} {
+		if err := repo.CreateAlbum(ctx, album); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		q    AlbumQuery
+		want []int
+	}{
+		{AlbumQuery{}, []int{2, 3, 1}},
+		{AlbumQuery{ArtistID: 1}, []int{3, 1}},
+		{AlbumQuery{Title: "HOLES"}, []int{1}},
+		{AlbumQuery{Offset: 1}, []int{3, 1}},
+	}
+	for _, tt := range tests {
+		tt.q.Limit = 10
+		page, err := repo.GetAlbums(ctx, tt.q)
+		if err != nil {
+			t.Errorf("GetAlbums(%+v): %v", tt.q, err)
+			continue
+		}
+		var got []int
+		for _, album := range page.Items {
+			got = append(got, album.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetAlbums(%+v) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+}
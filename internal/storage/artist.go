@@ -0,0 +1,195 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/fevse/songlib/internal/translit"
+)
+
+// normalizeName trims the name and collapses runs of spaces.
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// nameKey identifies an artist regardless of case and spacing, "Muse" and
+// "muse " have the same key.
+func nameKey(name string) string {
+	return strings.ToLower(normalizeName(name))
+}
+
+const artistColumns = `id, name, description`
+
+func scanArtist(row scanner, artist *Artist) error {
+	return row.Scan(&artist.ID, &artist.Name, &artist.Description)
+}
+
+func (r *Storage) CreateArtist(ctx context.Context, artist *Artist) error {
+	artist.Name = normalizeName(artist.Name)
+
+	query := `
+		INSERT INTO artists (name, name_key, description)
+		VALUES ($1, $2, $3)
+		RETURNING id`
+	err := r.conn().QueryRow(ctx, query, artist.Name, nameKey(artist.Name), artist.Description).Scan(&artist.ID)
+	if err != nil {
+		log.Printf("Error creating artist: %v", err)
+		return mapError(err)
+	}
+	return nil
+}
+
+func (r *Storage) GetArtist(ctx context.Context, id int) (*Artist, error) {
+	return getArtist(ctx, r.conn(), `id = $1`, id)
+}
+
+func getArtist(ctx context.Context, c conn, where string, arg any) (*Artist, error) {
+	query := `SELECT ` + artistColumns + ` FROM artists WHERE ` + where
+
+	var artist Artist
+	err := scanArtist(c.QueryRow(ctx, query, arg), &artist)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		log.Printf("Error getting artist: %v", err)
+		return nil, err
+	}
+	return &artist, nil
+}
+
+// ensureArtist returns the artist with the given name, creating it when
+// there is none yet.
+func ensureArtist(ctx context.Context, c conn, name string) (*Artist, error) {
+	key := nameKey(name)
+
+	query := `
+		INSERT INTO artists (name, name_key)
+		VALUES ($1, $2)
+		ON CONFLICT (name_key) DO NOTHING`
+	if _, err := c.Exec(ctx, query, normalizeName(name), key); err != nil {
+		log.Printf("Error creating artist: %v", err)
+		return nil, err
+	}
+	return getArtist(ctx, c, `name_key = $1`, key)
+}
+
+// resolveArtist links the song to its artist: by artistId when it is set,
+// otherwise by group, creating the artist if needed. Group is replaced
+// with the artist name, so that all songs of an artist spell it the same.
+func resolveArtist(ctx context.Context, c conn, song *Song) error {
+	if song.ArtistID == 0 {
+		artist, err := ensureArtist(ctx, c, song.Group)
+		if err != nil {
+			return err
+		}
+		song.ArtistID, song.Group = artist.ID, artist.Name
+		return nil
+	}
+
+	artist, err := getArtist(ctx, c, `id = $1`, song.ArtistID)
+	if errors.Is(err, ErrNotFound) {
+		return ErrUnknownArtist
+	} else if err != nil {
+		return err
+	}
+	return linkArtist(song, artist)
+}
+
+// linkArtist sets the song group to the artist name, a group naming
+// another artist is rejected.
+func linkArtist(song *Song, artist *Artist) error {
+	if song.Group != "" && nameKey(song.Group) != nameKey(artist.Name) {
+		return ErrArtistMismatch
+	}
+	song.Group = artist.Name
+	return nil
+}
+
+// UpdateArtist renames the artist and the group of all its songs.
+func (r *Storage) UpdateArtist(ctx context.Context, artist *Artist) error {
+	artist.Name = normalizeName(artist.Name)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	c := conn{q: tx, dialect: &r.dialect}
+	query := `UPDATE artists SET name = $1, name_key = $2, description = $3 WHERE id = $4`
+	res, err := c.Exec(ctx, query, artist.Name, nameKey(artist.Name), artist.Description, artist.ID)
+	if err != nil {
+		log.Printf("Error updating artist: %v", err)
+		return mapError(err)
+	}
+	if err := checkAffected(res); err != nil {
+		return err
+	}
+
+	query = `UPDATE songs SET band = $1, band_key = $2 WHERE artist_id = $3`
+	if _, err := c.Exec(ctx, query, artist.Name, translit.Key(artist.Name), artist.ID); err != nil {
+		log.Printf("Error renaming artist songs: %v", err)
+		return mapError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteArtist deletes an artist without songs, an artist that still has
+// songs is a conflict.
+func (r *Storage) DeleteArtist(ctx context.Context, id int) error {
+	res, err := r.conn().Exec(ctx, `DELETE FROM artists WHERE id = $1`, id)
+	if err != nil {
+		log.Printf("Error deleting artist: %v", err)
+		return mapError(err)
+	}
+	return checkAffected(res)
+}
+
+func (r *Storage) GetArtists(ctx context.Context, q ArtistQuery) (*ArtistPage, error) {
+	where, args := " WHERE 1=1", []any{}
+	if q.Name != "" {
+		args = append(args, "%"+escapeLike(nameKey(q.Name))+"%")
+		where += ` AND name_key LIKE $1 ESCAPE '\'`
+	}
+
+	page := &ArtistPage{Items: []Artist{}, Limit: q.Limit, Offset: q.Offset}
+	if err := r.conn().QueryRow(ctx, `SELECT count(*) FROM artists`+where, args...).Scan(&page.Total); err != nil {
+		log.Printf("Error counting artists: %v", err)
+		return nil, err
+	}
+
+	args = append(args, q.Limit, q.Offset)
+	query := `SELECT ` + artistColumns + ` FROM artists` + where +
+		` ORDER BY name_key, id LIMIT $` + strconv.Itoa(len(args)-1) + ` OFFSET $` + strconv.Itoa(len(args))
+
+	rows, err := r.conn().Query(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error getting artists: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var artist Artist
+		if err := scanArtist(rows, &artist); err != nil {
+			log.Printf("Error scanning artist: %v", err)
+			return nil, err
+		}
+		page.Items = append(page.Items, artist)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error getting artists: %v", err)
+		return nil, err
+	}
+	return page, nil
+}
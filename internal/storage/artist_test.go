@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestArtists(t *testing.T) {
+	forEachRepository(t, testArtists)
+}
+
+func testArtists(t *testing.T, newRepo func(...*Song) Repository) {
+	ctx := context.Background()
+	repo := newRepo()
+
+	muse := &Artist{Name: "  Muse  "}
+	if err := repo.CreateArtist(ctx, muse); err != nil {
+		t.Fatal(err)
+	}
+	if muse.Name != "Muse" {
+		t.Errorf("created artist name = %q, want Muse", muse.Name)
+	}
+	if err := repo.CreateArtist(ctx, &Artist{Name: "muse"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("CreateArtist of the same name err = %v, want ErrConflict", err)
+	}
+
+	// A song is linked to its artist by group, a new group adds an artist.
+	hole := &Song{Group: "MUSE", Song: "Hole"}
+	kukushka := &Song{Group: "Kino", Song: "Kukushka"}
+	for _, song := range []*Song{hole, kukushka} {
+		if err := repo.Create(ctx, song); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if hole.ArtistID != muse.ID || hole.Group != "Muse" {
+		t.Errorf("song of MUSE = %d %q, want %d Muse", hole.ArtistID, hole.Group, muse.ID)
+	}
+	if kukushka.ArtistID == 0 || kukushka.ArtistID == muse.ID {
+		t.Errorf("song of Kino artist = %d, want a new artist", kukushka.ArtistID)
+	}
+
+	if err := repo.Create(ctx, &Song{ArtistID: 99, Song: "Hole"}); !errors.Is(err, ErrUnknownArtist) {
+		t.Errorf("Create with a missing artist err = %v, want ErrUnknownArtist", err)
+	}
+	if err := repo.Create(ctx, &Song{ArtistID: muse.ID, Group: "Kino", Song: "Hole"}); !errors.Is(err, ErrArtistMismatch) {
+		t.Errorf("Create with another group err = %v, want ErrArtistMismatch", err)
+	}
+
+	// Renaming the artist renames the group of its songs.
+	muse.Name = "Muse UK"
+	if err := repo.UpdateArtist(ctx, muse); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := repo.GetByID(ctx, hole.ID); got.Group != "Muse UK" {
+		t.Errorf("group after renaming the artist = %q, want Muse UK", got.Group)
+	}
+	if err := repo.UpdateArtist(ctx, &Artist{ID: muse.ID, Name: "kino"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("UpdateArtist to a taken name err = %v, want ErrConflict", err)
+	}
+	if err := repo.UpdateArtist(ctx, &Artist{ID: 99, Name: "Nobody"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("UpdateArtist of a missing artist err = %v, want ErrNotFound", err)
+	}
+
+	page, err := repo.GetList(ctx, ListQuery{Filter: Filter{{Field: "artistId", Op: OpEq, Value: "1"}}, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := songIDs(page.Items); !reflect.DeepEqual(got, []int{hole.ID}) {
+		t.Errorf("songs of artist 1 = %v, want [%d]", got, hole.ID)
+	}
+
+	if err := repo.DeleteArtist(ctx, muse.ID); !errors.Is(err, ErrConflict) {
+		t.Errorf("DeleteArtist with songs err = %v, want ErrConflict", err)
+	}
+	if err := repo.Delete(ctx, hole.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DeleteArtist(ctx, muse.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetArtist(ctx, muse.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetArtist after DeleteArtist err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestGetArtists(t *testing.T) {
+	forEachRepository(t, testGetArtists)
+}
+
+func testGetArtists(t *testing.T, newRepo func(...*Song) Repository) {
+	ctx := context.Background()
+	repo := newRepo(
+		&Song{Group: "Muse", Song: "Hole"},
+		&Song{Group: "Kino", Song: "Kukushka"},
+		&Song{Group: "Muse", Song: "Uprising"},
+		&Song{Group: "Mumiy Troll", Song: "Vladivostok 2000"},
+	)
+
+	tests := []struct {
+		q    ArtistQuery
+		want []string
+	}{
+		{ArtistQuery{}, []string{"Kino", "Mumiy Troll", "Muse"}},
+		{ArtistQuery{Name: "MU"}, []string{"Mumiy Troll", "Muse"}},
+		{ArtistQuery{Offset: 2}, []string{"Muse"}},
+		{ArtistQuery{Name: "queen"}, nil},
+	}
+	for _, tt := range tests {
+		tt.q.Limit = 10
+		page, err := repo.GetArtists(ctx, tt.q)
+		if err != nil {
+			t.Errorf("GetArtists(%+v): %v", tt.q, err)
+			continue
+		}
+		var got []string
+		for _, artist := range page.Items {
+			got = append(got, artist.Name)
+		}
+		if !reflect.DeepEqual(got, tt.want) || (tt.q.Offset == 0 && page.Total != len(tt.want)) {
+			t.Errorf("GetArtists(%+v) = %q of %d, want %q", tt.q, got, page.Total, tt.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/pressly/goose/v3"
+
+	"github.com/fevse/songlib/internal/translit"
+)
+
+// Migrations that derive keys from names fill them in Go, so that existing
+// rows get the keys the application writes for new ones: lower() of SQLite
+// folds ASCII letters only and that of Postgres depends on the collation.
+// They run for both dialects, queries take $N placeholders in both.
+func init() {
+	goose.AddNamedMigrationContext("20250410120001_artists_backfill.go", backfillArtists, nil)
+	goose.AddNamedMigrationContext("20250510120001_translit_keys_backfill.go", backfillSongKeys, nil)
+}
+
+// backfillArtists creates an artist for every band of the songs and links
+// the songs to it. Bands with the same nameKey are one artist, named by
+// the first of their normalized spellings.
+func backfillArtists(ctx context.Context, tx *sql.Tx) error {
+	bands, err := queryStrings(ctx, tx, `SELECT DISTINCT band FROM songs`)
+	if err != nil {
+		return err
+	}
+	slices.Sort(bands)
+
+	artists := make(map[string]Artist)
+	for _, band := range bands {
+		key := nameKey(band)
+		if key == "" {
+			continue
+		}
+
+		artist, ok := artists[key]
+		if !ok {
+			artist.Name = normalizeName(band)
+			query := `INSERT INTO artists (name, name_key) VALUES ($1, $2) RETURNING id`
+			if err := tx.QueryRowContext(ctx, query, artist.Name, key).Scan(&artist.ID); err != nil {
+				return err
+			}
+			artists[key] = artist
+		}
+
+		query := `UPDATE songs SET artist_id = $1, band = $2 WHERE band = $3`
+		if _, err := tx.ExecContext(ctx, query, artist.ID, artist.Name, band); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillSongKeys sets band_key and song_key of every song with
+// translit.Key.
+func backfillSongKeys(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id, band, song FROM songs`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type names struct {
+		id         int
+		band, song string
+	}
+	var songs []names
+	for rows.Next() {
+		var n names
+		if err := rows.Scan(&n.id, &n.band, &n.song); err != nil {
+			return err
+		}
+		songs = append(songs, n)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, n := range songs {
+		query := `UPDATE songs SET band_key = $1, song_key = $2 WHERE id = $3`
+		if _, err := tx.ExecContext(ctx, query, translit.Key(n.band), translit.Key(n.song), n.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryStrings returns the single text column of the rows of the query.
+func queryStrings(ctx context.Context, tx *sql.Tx, query string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
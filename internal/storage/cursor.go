@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursor points right after the last row of a page: it keeps the sort
+// key values and the id of that row. Sort is stored to reject a cursor
+// issued for a different ordering.
+type cursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+	ID     int      `json:"id"`
+}
+
+func encodeCursor(sort Sort, song Song) string {
+	c := cursor{Sort: sort.String(), ID: song.ID}
+	for _, key := range sort {
+		c.Values = append(c.Values, song.sortValue(key.Field))
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(token string, sort Sort) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != sort.String() || len(c.Values) != len(sort) {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// after builds the keyset condition selecting rows that follow the cursor
+// in the given order: (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... OR
+// (k1 = v1 AND ... AND id > cursor id).
+func (c *cursor) after(sort Sort, args []any) (string, []any) {
+	var terms []string
+	var equal []string
+	for i, key := range sort {
+		column := songFields[key.Field].sortExpr()
+		args = append(args, c.Values[i])
+		n := "$" + strconv.Itoa(len(args))
+
+		op := " > "
+		if key.Desc {
+			op = " < "
+		}
+		terms = append(terms, "("+strings.Join(append(equal, column+op+n), " AND ")+")")
+		equal = append(equal, column+" = "+n)
+	}
+
+	args = append(args, c.ID)
+	n := "$" + strconv.Itoa(len(args))
+	terms = append(terms, "("+strings.Join(append(equal, "id > "+n), " AND ")+")")
+
+	return " AND (" + strings.Join(terms, " OR ") + ")", args
+}
+
+// follows reports whether the song comes after the cursor, the in-memory
+// counterpart of after.
+func (c *cursor) follows(sort Sort, song Song) bool {
+	last := Song{ID: c.ID}
+	for i, key := range sort {
+		last.setFieldValue(key.Field, c.Values[i])
+	}
+	return sort.compare(song, last) > 0
+}
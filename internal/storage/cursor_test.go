@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		sort Sort
+		song Song
+		want []string
+	}{
+		{nil, Song{ID: 3}, nil},
+		{Sort{{Field: "group"}}, Song{ID: 4, Group: "Muse"}, []string{"Muse"}},
+		{Sort{{Field: "releaseDate", Desc: true}, {Field: "song"}}, Song{ID: 5, Song: "Uprising"}, []string{"0001-01-01", "Uprising"}},
+	}
+	for _, tt := range tests {
+		token := encodeCursor(tt.sort, tt.song)
+		c, err := decodeCursor(token, tt.sort)
+		if err != nil {
+			t.Errorf("decodeCursor(%q): %v", tt.sort, err)
+			continue
+		}
+		if c.ID != tt.song.ID || !reflect.DeepEqual(c.Values, tt.want) {
+			t.Errorf("decodeCursor(%q) = %d %q, want %d %q", tt.sort, c.ID, c.Values, tt.song.ID, tt.want)
+		}
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	bySong := Sort{{Field: "song"}}
+	tests := []struct {
+		name  string
+		token string
+		sort  Sort
+	}{
+		{"not base64", "***", bySong},
+		{"not JSON", base64.RawURLEncoding.EncodeToString([]byte("{")), bySong},
+		{"other sort", encodeCursor(Sort{{Field: "group"}}, Song{ID: 1}), bySong},
+		{"other direction", encodeCursor(Sort{{Field: "song", Desc: true}}, Song{ID: 1}), bySong},
+		{"missing values", base64.RawURLEncoding.EncodeToString([]byte(`{"s":"song","id":1}`)), bySong},
+	}
+	for _, tt := range tests {
+		if _, err := decodeCursor(tt.token, tt.sort); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: err = %v, want ErrInvalidCursor", tt.name, err)
+		}
+	}
+}
+
+func TestCursorAfter(t *testing.T) {
+	tests := []struct {
+		sort     Sort
+		values   []string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			nil, nil,
+			" AND ((id > $2))",
+			[]any{"x", 7},
+		},
+		{
+			Sort{{Field: "group"}}, []string{"Muse"},
+			" AND ((band > $2) OR (band = $2 AND id > $3))",
+			[]any{"x", "Muse", 7},
+		},
+		{
+			Sort{{Field: "releaseDate", Desc: true}, {Field: "song"}}, []string{"2006-01-01", "Hole"},
+			" AND ((COALESCE(release_date, '0001-01-01') < $2)" +
+				" OR (COALESCE(release_date, '0001-01-01') = $2 AND song > $3)" +
+				" OR (COALESCE(release_date, '0001-01-01') = $2 AND song = $3 AND id > $4))",
+			[]any{"x", "2006-01-01", "Hole", 7},
+		},
+	}
+	for _, tt := range tests {
+		c := &cursor{Sort: tt.sort.String(), Values: tt.values, ID: 7}
+		sql, args := c.after(tt.sort, []any{"x"})
+		if sql != tt.wantSQL {
+			t.Errorf("after(%q) SQL = %q, want %q", tt.sort, sql, tt.wantSQL)
+		}
+		if !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("after(%q) args = %v, want %v", tt.sort, args, tt.wantArgs)
+		}
+	}
+}
+
+func TestCursorFollows(t *testing.T) {
+	byDateDesc := Sort{{Field: "releaseDate", Desc: true}}
+	c := &cursor{Sort: byDateDesc.String(), Values: []string{"2006-01-01"}, ID: 5}
+	tests := []struct {
+		song Song
+		want bool
+	}{
+		{Song{ID: 1, ReleaseDate: "2007-01-01"}, false},
+		{Song{ID: 1, ReleaseDate: "2006-01-01"}, false},
+		{Song{ID: 5, ReleaseDate: "2006-01-01"}, false},
+		{Song{ID: 6, ReleaseDate: "2006-01-01"}, true},
+		{Song{ID: 1, ReleaseDate: "2005-01-01"}, true},
+		// Songs without a date sort as 0001-01-01, last in descending order.
+		{Song{ID: 1}, true},
+	}
+	for _, tt := range tests {
+		if got := c.follows(byDateDesc, tt.song); got != tt.want {
+			t.Errorf("follows(%d, %q) = %v, want %v", tt.song.ID, tt.song.ReleaseDate, got, tt.want)
+		}
+	}
+}
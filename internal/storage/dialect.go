@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"regexp"
+
+	"github.com/lib/pq"
+)
+
+// dialect describes what differs between the supported SQL databases.
+// Queries are written with Postgres placeholders ($1, $2, ...) and
+// rewritten by rebind where needed.
+type dialect struct {
+	goose string
+	// migrations is the directory of the dialect migrations within
+	// the migrations package.
+	migrations string
+	// like is a case-insensitive LIKE operator.
+	like string
+	// lock is appended to a SELECT that must lock the rows it reads.
+	lock string
+	// numbered is the prefix of numbered placeholders.
+	numbered string
+	// textSearch reports whether songs have the search_vector column and
+	// pg_trgm is installed, otherwise songs are searched in Go.
+	textSearch bool
+	// arrays reports whether a parameter can be an array, otherwise lists
+	// are passed as JSON arrays.
+	arrays bool
+}
+
+var dialects = map[string]dialect{
+	"postgres": {
+		goose:      "postgres",
+		migrations: ".",
+		like:       "ILIKE",
+		lock:       " FOR UPDATE",
+		numbered:   "$",
+		textSearch: true,
+		arrays:     true,
+	},
+	// SQLite has no row locks, writes are serialized by the database.
+	"sqlite": {
+		goose:      "sqlite3",
+		migrations: "sqlite",
+		like:       "LIKE",
+		numbered:   "?",
+	},
+}
+
+var placeholder = regexp.MustCompile(`\$(\d+)`)
+
+func (d *dialect) rebind(query string) string {
+	if d.numbered == "$" {
+		return query
+	}
+	return placeholder.ReplaceAllString(query, d.numbered+"$1")
+}
+
+// anyID matches the column against the ids bound to the parameter as a
+// whole, so that the number of ids is not limited by the number of
+// parameters of a statement.
+func (d *dialect) anyID(column, param string, ids []int) (string, any) {
+	if d.arrays {
+		return column + " = ANY(" + param + ")", pq.Array(ids)
+	}
+	list, _ := json.Marshal(ids)
+	return column + " IN (SELECT value FROM json_each(" + param + "))", string(list)
+}
+
+// conn passes queries to the database or transaction rewritten for the
+// dialect.
+type conn struct {
+	q       querier
+	dialect *dialect
+}
+
+func (c conn) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return c.q.ExecContext(ctx, c.dialect.rebind(query), args...)
+}
+
+func (c conn) Query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return c.q.QueryContext(ctx, c.dialect.rebind(query), args...)
+}
+
+func (c conn) QueryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	return c.q.QueryRowContext(ctx, c.dialect.rebind(query), args...)
+}
@@ -0,0 +1,228 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/fevse/songlib/internal/translit"
+)
+
+// ErrNotDuplicate is returned when a song merged into another one has a
+// different band or title.
+var ErrNotDuplicate = errors.New("not a duplicate")
+
+// DefaultDuplicateScore lets through lyrics that differ in formatting or
+// a few lines, but not other songs under the same title.
+const DefaultDuplicateScore = 0.5
+
+// Duplicates finds groups of songs with the same band and title keys and
+// similar lyrics. Songs with an empty key are not compared, their names
+// have no letters or digits. Once songs are unique, see SetUniqueSongs,
+// there are none.
+func (r *Storage) Duplicates(ctx context.Context, q DuplicateQuery) (*DuplicatePage, error) {
+	// Only songs sharing their keys with another song are read, the lyrics
+	// are compared in Go.
+	query := `
+		SELECT ` + songColumns + ` FROM songs
+		WHERE (band_key, song_key) IN (
+			SELECT band_key, song_key FROM songs
+			WHERE band_key <> '' AND song_key <> ''
+			GROUP BY band_key, song_key HAVING count(*) > 1)`
+	rows, err := r.conn().Query(ctx, query)
+	if err != nil {
+		log.Printf("Error finding duplicates: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var songs []Song
+	for rows.Next() {
+		var song Song
+		if err := scanSong(rows, &song); err != nil {
+			log.Printf("Error scanning song: %v", err)
+			return nil, err
+		}
+		songs = append(songs, song)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error finding duplicates: %v", err)
+		return nil, err
+	}
+
+	page := duplicateGroups(songs, q)
+	if err := loadTags(ctx, r.conn(), groupSongs(page.Items)...); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func groupSongs(groups []DuplicateGroup) []*Song {
+	var songs []*Song
+	for i := range groups {
+		for j := range groups[i].Songs {
+			songs = append(songs, &groups[i].Songs[j].Song)
+		}
+	}
+	return songs
+}
+
+// duplicateGroups groups the songs by their keys, the group is kept when
+// the lyrics of at least one song are similar enough to the oldest one.
+func duplicateGroups(songs []Song, q DuplicateQuery) *DuplicatePage {
+	slices.SortFunc(songs, func(a, b Song) int { return cmp.Compare(a.ID, b.ID) })
+
+	type key struct{ band, song string }
+	var keys []key
+	byKey := make(map[key][]Song)
+	for _, song := range songs {
+		k := key{translit.Key(song.Group), translit.Key(song.Song)}
+		if k.band == "" || k.song == "" {
+			continue
+		}
+		if _, ok := byKey[k]; !ok {
+			keys = append(keys, k)
+		}
+		byKey[k] = append(byKey[k], song)
+	}
+
+	var groups []DuplicateGroup
+	for _, k := range keys {
+		first := byKey[k][0]
+		group := DuplicateGroup{Group: first.Group, Song: first.Song, Songs: []Duplicate{{Song: first, Score: 1}}}
+		for _, song := range byKey[k][1:] {
+			if score := lyricsSimilarity(first.Text, song.Text); score >= q.MinScore {
+				group.Songs = append(group.Songs, Duplicate{Song: song, Score: score})
+			}
+		}
+		if len(group.Songs) > 1 {
+			groups = append(groups, group)
+		}
+	}
+
+	page := &DuplicatePage{Items: []DuplicateGroup{}, Total: len(groups), Limit: q.Limit, Offset: q.Offset}
+	groups = groups[min(q.Offset, len(groups)):]
+	page.Items = append(page.Items, groups[:min(q.Limit, len(groups))]...)
+	return page
+}
+
+// lyricsSimilarity is the trigram similarity of two lyrics. Missing
+// lyrics tell nothing apart, they are similar to any.
+func lyricsSimilarity(a, b string) float64 {
+	if translit.Key(a) == "" || translit.Key(b) == "" {
+		return 1
+	}
+	return similarity(trigrams(a), trigrams(b))
+}
+
+// Merge folds the duplicates into the song: their playlist entries and
+// tags move to the song, blank fields of the song are filled in from them
+// and their ids redirect to the song. The duplicates are deleted. Unless
+// force is set, a duplicate must have the band and title keys of the song.
+func (r *Storage) Merge(ctx context.Context, id int, duplicates []int, force bool) (*Song, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	c := conn{q: tx, dialect: &r.dialect}
+	song, err := getByID(ctx, c, id, r.dialect.lock)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dupID := range duplicates {
+		dup, err := getByID(ctx, c, dupID, r.dialect.lock)
+		if errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("%w: %d", ErrUnknownSong, dupID)
+		} else if err != nil {
+			return nil, err
+		}
+		if !force && !sameKeys(song, dup) {
+			return nil, fmt.Errorf("%w: %d", ErrNotDuplicate, dupID)
+		}
+		foldSong(song, dup)
+
+		statements := []struct {
+			query string
+			args  []any
+		}{
+			{`UPDATE playlist_entries SET song_id = $1 WHERE song_id = $2`, []any{id, dupID}},
+			{`INSERT INTO song_tags (song_id, tag_id)
+				SELECT $1, tag_id FROM song_tags WHERE song_id = $2
+				ON CONFLICT DO NOTHING`, []any{id, dupID}},
+			{`UPDATE song_redirects SET song_id = $1 WHERE song_id = $2`, []any{id, dupID}},
+			{`INSERT INTO song_redirects (id, song_id) VALUES ($1, $2)`, []any{dupID, id}},
+			// The duplicate goes first, it may hold the album track the song
+			// takes over.
+			{`DELETE FROM songs WHERE id = $1`, []any{dupID}},
+		}
+		for _, s := range statements {
+			if _, err := c.Exec(ctx, s.query, s.args...); err != nil {
+				log.Printf("Error merging song: %v", err)
+				return nil, mapError(err)
+			}
+		}
+	}
+
+	query := `
+		UPDATE songs
+		SET release_date = $1, release_date_precision = $2, text = $3, link = $4,
+			album_id = $5, disc_number = $6, track_number = $7
+		WHERE id = $8`
+	_, err = c.Exec(ctx, query, nullString(song.ReleaseDate), nullString(song.ReleaseDatePrecision), song.Text, song.Link,
+		nullInt(song.AlbumID), nullInt(song.DiscNumber), nullInt(song.TrackNumber), id)
+	if err != nil {
+		log.Printf("Error merging song: %v", err)
+		return nil, mapError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+// sameKeys reports whether the songs have the same band and title up to
+// case, punctuation and script. Empty keys tell nothing, they differ.
+func sameKeys(a, b *Song) bool {
+	band, key := translit.Key(a.Group), translit.Key(a.Song)
+	return band != "" && key != "" && band == translit.Key(b.Group) && key == translit.Key(b.Song)
+}
+
+// foldSong fills in the blank fields of the song from its duplicate, the
+// song takes over the album track of the duplicate if it has none.
+func foldSong(song, dup *Song) {
+	if song.ReleaseDate == "" {
+		song.ReleaseDate, song.ReleaseDatePrecision = dup.ReleaseDate, dup.ReleaseDatePrecision
+	}
+	if song.Text == "" {
+		song.Text = dup.Text
+	}
+	if song.Link == "" {
+		song.Link = dup.Link
+	}
+	if song.AlbumID == 0 {
+		song.AlbumID, song.DiscNumber, song.TrackNumber = dup.AlbumID, dup.DiscNumber, dup.TrackNumber
+	}
+}
+
+// Redirect returns the song a merged duplicate id redirects to.
+func (r *Storage) Redirect(ctx context.Context, id int) (int, error) {
+	var songID int
+	err := r.conn().QueryRow(ctx, `SELECT song_id FROM song_redirects WHERE id = $1`, id).Scan(&songID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	} else if err != nil {
+		log.Printf("Error getting song redirect: %v", err)
+		return 0, err
+	}
+	return songID, nil
+}
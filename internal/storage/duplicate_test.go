@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestDuplicateGroups(t *testing.T) {
+	songs := []Song{
+		{ID: 5, Group: "MUSE", Song: "hole"},
+		{ID: 1, Group: "Кино", Song: "Группа крови", Text: "Теплое место, но улицы ждут отпечатков наших ног"},
+		{ID: 2, Group: "Muse", Song: "Hole"},
+		{ID: 3, Group: "kino", Song: "gruppa krovi!"},
+		{ID: 4, Group: "Kino", Song: "Gruppa Krovi", Text: "la la la la la"},
+		{ID: 6, Group: "Muse", Song: "Uprising"},
+		// Names without letters or digits have no key, they are not
+		// duplicates of each other.
+		{ID: 7, Group: "!!!", Song: "?"},
+		{ID: 8, Group: "...", Song: "?"},
+	}
+
+	groupIDs := func(page *DuplicatePage) [][]int {
+		ids := [][]int{}
+		for _, group := range page.Items {
+			var songs []int
+			for _, dup := range group.Songs {
+				songs = append(songs, dup.ID)
+			}
+			ids = append(ids, songs)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		q     DuplicateQuery
+		want  [][]int
+		total int
+	}{
+		// Song 4 has other lyrics, song 3 has none.
+		{DuplicateQuery{MinScore: DefaultDuplicateScore, Limit: 10}, [][]int{{1, 3}, {2, 5}}, 2},
+		{DuplicateQuery{MinScore: 0, Limit: 10}, [][]int{{1, 3, 4}, {2, 5}}, 2},
+		{DuplicateQuery{MinScore: DefaultDuplicateScore, Limit: 1, Offset: 1}, [][]int{{2, 5}}, 2},
+		{DuplicateQuery{MinScore: DefaultDuplicateScore, Limit: 10, Offset: 5}, [][]int{}, 2},
+	}
+	for _, tt := range tests {
+		page := duplicateGroups(slices.Clone(songs), tt.q)
+		if got := groupIDs(page); !reflect.DeepEqual(got, tt.want) || page.Total != tt.total {
+			t.Errorf("duplicateGroups(%+v) = %v of %d, want %v of %d", tt.q, got, page.Total, tt.want, tt.total)
+		}
+	}
+
+	page := duplicateGroups(slices.Clone(songs), DuplicateQuery{MinScore: 0, Limit: 1})
+	if group := page.Items[0]; group.Group != "Кино" || group.Song != "Группа крови" || group.Songs[0].Score != 1 || group.Songs[2].Score >= DefaultDuplicateScore {
+		t.Errorf("group = %+v, want the names of the first song and a low score of song 4", group)
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	forEachRepository(t, testDuplicates)
+}
+
+// testDuplicates merges the duplicates it finds, songs can be kept unique
+// after that.
+func testDuplicates(t *testing.T, newRepo func(...*Song) Repository) {
+	ctx := context.Background()
+	repo := newRepo(
+		&Song{Group: "Кино", Song: "Группа крови"},
+		&Song{Group: "Muse", Song: "Hole"},
+		&Song{Group: "kino", Song: "gruppa krovi", Text: "Теплое место", Link: "https://example.com/krovi"},
+		&Song{Group: "KINO", Song: "Gruppa Krovi!", Text: "Other lyrics"},
+		&Song{Group: "!!!", Song: "?"},
+		&Song{Group: "...", Song: "?"},
+	)
+	if err := repo.AddTags(ctx, 4, []Tag{{Name: "rock"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := repo.Duplicates(ctx, DuplicateQuery{MinScore: 0, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 1 || len(page.Items[0].Songs) != 3 || !reflect.DeepEqual(page.Items[0].Songs[2].Tags, []string{"rock"}) {
+		t.Fatalf("Duplicates = %+v, want songs 1, 3 and 4 with their tags", page)
+	}
+	if _, err := repo.Merge(ctx, 5, []int{6}, false); !errors.Is(err, ErrNotDuplicate) {
+		t.Errorf("Merge of songs without keys err = %v, want ErrNotDuplicate", err)
+	}
+	if err := repo.(uniqueSongs).SetUniqueSongs(ctx, true); !errors.Is(err, ErrDuplicateSongs) {
+		t.Errorf("SetUniqueSongs with duplicates err = %v, want ErrDuplicateSongs", err)
+	}
+
+	song, err := repo.Merge(ctx, 1, []int{3, 4}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Blank fields are taken from the oldest duplicate that has them.
+	if song.Text != "Теплое место" || song.Link != "https://example.com/krovi" || !reflect.DeepEqual(song.Tags, []string{"rock"}) {
+		t.Errorf("merged song = %+v, want the lyrics, link and tags of the duplicates", song)
+	}
+	if page, err := repo.Duplicates(ctx, DuplicateQuery{MinScore: 0, Limit: 10}); err != nil || page.Total != 0 {
+		t.Errorf("Duplicates after the merge = %+v, %v, want none", page, err)
+	}
+	if err := repo.(uniqueSongs).SetUniqueSongs(ctx, true); err != nil {
+		t.Errorf("SetUniqueSongs after the merge: %v", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	forEachRepository(t, testMerge)
+}
+
+func testMerge(t *testing.T, newRepo func(...*Song) Repository) {
+	ctx := context.Background()
+	repo := newRepo(
+		&Song{Group: "Кино", Song: "Группа крови", Text: "Теплое место"},
+		&Song{Group: "kino", Song: "gruppa krovi (live)", Text: "Other lyrics", Link: "https://example.com/krovi",
+			ReleaseDate: "1988-01-01", ReleaseDatePrecision: "year"},
+		&Song{Group: "KINO", Song: "Gruppa krovi (demo)"},
+		&Song{Group: "Muse", Song: "Hole"},
+	)
+	if err := repo.AddTags(ctx, 1, []Tag{{Name: "rock"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.AddTags(ctx, 2, []Tag{{Name: "rock"}, {Name: "80s"}}); err != nil {
+		t.Fatal(err)
+	}
+	playlist := &Playlist{Name: "Road trip"}
+	if err := repo.CreatePlaylist(ctx, playlist); err != nil {
+		t.Fatal(err)
+	}
+	for _, songID := range []int{2, 4, 3} {
+		if _, err := repo.InsertEntry(ctx, playlist.ID, PlaylistInsert{SongID: songID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := repo.Merge(ctx, 9, []int{2}, true); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Merge into a missing song err = %v, want ErrNotFound", err)
+	}
+	if _, err := repo.Merge(ctx, 1, []int{2, 9}, true); !errors.Is(err, ErrUnknownSong) {
+		t.Errorf("Merge of a missing duplicate err = %v, want ErrUnknownSong", err)
+	}
+	if _, err := repo.GetByID(ctx, 2); err != nil {
+		t.Fatalf("duplicate after a failed merge: %v", err)
+	}
+
+	// Song 2 is a live version, it is only merged when forced.
+	if _, err := repo.Merge(ctx, 1, []int{2}, false); !errors.Is(err, ErrNotDuplicate) {
+		t.Errorf("Merge of another title err = %v, want ErrNotDuplicate", err)
+	}
+	song, err := repo.Merge(ctx, 1, []int{2}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Blank fields are filled in, the lyrics of the song are kept.
+	want := Song{ID: 1, Group: "Кино", Song: "Группа крови", Text: "Теплое место", Link: "https://example.com/krovi",
+		ReleaseDate: "1988-01-01", ReleaseDatePrecision: "year", Tags: []string{"80s", "rock"}}
+	song.ArtistID = 0
+	if !reflect.DeepEqual(*song, want) {
+		t.Errorf("merged song = %+v, want %+v", *song, want)
+	}
+	if _, err := repo.GetByID(ctx, 2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetByID of a merged duplicate err = %v, want ErrNotFound", err)
+	}
+
+	// A redirect to a duplicate follows it when it is merged in turn.
+	if _, err := repo.Merge(ctx, 4, []int{3}, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Merge(ctx, 1, []int{4}, true); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int{2, 3, 4} {
+		if got, err := repo.Redirect(ctx, id); err != nil || got != 1 {
+			t.Errorf("Redirect(%d) = %d, %v, want 1", id, got, err)
+		}
+	}
+	if _, err := repo.Redirect(ctx, 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Redirect of a song err = %v, want ErrNotFound", err)
+	}
+
+	got, err := repo.GetPlaylist(ctx, playlist.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := entrySongs(t, got); !reflect.DeepEqual(ids, []int{1, 1, 1}) {
+		t.Errorf("playlist entries after the merge = %v, want [1 1 1]", ids)
+	}
+}
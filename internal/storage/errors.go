@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+var (
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("conflict")
+	// ErrUnknownArtist is returned when a song refers to an artist that
+	// does not exist.
+	ErrUnknownArtist = errors.New("unknown artist")
+	// ErrArtistMismatch is returned when the group of a song names another
+	// artist than its artistId.
+	ErrArtistMismatch = errors.New("group does not match the artist")
+	// ErrDuplicateSongs is returned by SetUniqueSongs when songs already
+	// share their band and title.
+	ErrDuplicateSongs = errors.New("songs with the same band and title exist, merge them first")
+)
+
+// ConflictError is returned when a write violates a unique constraint or
+// deletes a row still referenced by others. It matches ErrConflict with
+// errors.Is.
+type ConflictError struct {
+	Constraint string
+}
+
+func (e *ConflictError) Error() string {
+	return "conflict: constraint " + e.Constraint + " violated"
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+)
+
+// mapError translates driver errors into the storage error types.
+func mapError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && (pqErr.Code == uniqueViolation || pqErr.Code == foreignKeyViolation) {
+		return &ConflictError{Constraint: pqErr.Constraint}
+	}
+
+	// SQLite does not report constraint names, only the columns:
+	// "UNIQUE constraint failed: songs.band, songs.song".
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE {
+		msg := sqliteErr.Error()
+		columns, _, _ := strings.Cut(msg[strings.LastIndex(msg, "failed: ")+len("failed: "):], " (")
+		return &ConflictError{Constraint: columns}
+	}
+	if errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY {
+		return &ConflictError{Constraint: "foreign key"}
+	}
+	return err
+}
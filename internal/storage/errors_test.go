@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestMapError(t *testing.T) {
+	unique := &pq.Error{Code: uniqueViolation, Constraint: "songs_band_song_key"}
+	other := errors.New("connection refused")
+
+	if err := mapError(fmt.Errorf("scan: %w", sql.ErrNoRows)); err != ErrNotFound {
+		t.Errorf("mapError(ErrNoRows) = %v, want ErrNotFound", err)
+	}
+	var conflict *ConflictError
+	if err := mapError(unique); !errors.Is(err, ErrConflict) || !errors.As(err, &conflict) || conflict.Constraint != unique.Constraint {
+		t.Errorf("mapError(unique violation) = %v, want a conflict on %s", err, unique.Constraint)
+	}
+	if err := mapError(other); err != other {
+		t.Errorf("mapError(%v) = %v, want it unchanged", other, err)
+	}
+}
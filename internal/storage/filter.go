@@ -0,0 +1,290 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type Operator string
+
+const (
+	OpEq       Operator = "eq"
+	OpNe       Operator = "ne"
+	OpContains Operator = "contains"
+	OpPrefix   Operator = "prefix"
+	OpGt       Operator = "gt"
+	OpGte      Operator = "gte"
+	OpLt       Operator = "lt"
+	OpLte      Operator = "lte"
+)
+
+type field struct {
+	column string
+	ops    []Operator
+	// null stands in for NULL when ordering, so that rows without a value
+	// keep a stable position in keyset pagination. Empty for NOT NULL
+	// columns.
+	null string
+	// noSort excludes the field from sorting.
+	noSort bool
+	// nullable fields are NULL when empty and then match no condition.
+	nullable bool
+	// integer fields take integer values only, compared as numbers.
+	integer bool
+}
+
+// sortExpr is the expression rows are ordered and compared by.
+func (f field) sortExpr() string {
+	if f.null == "" {
+		return f.column
+	}
+	return "COALESCE(" + f.column + ", '" + f.null + "')"
+}
+
+var (
+	textOps = []Operator{OpEq, OpNe, OpContains, OpPrefix}
+	dateOps = []Operator{OpEq, OpNe, OpGt, OpGte, OpLt, OpLte}
+	idOps   = []Operator{OpEq, OpNe}
+)
+
+// songFields maps public field names to table columns and the operators
+// allowed on them. Nothing outside this map ever reaches the query builder.
+var songFields = map[string]field{
+	"group":       {column: "band", ops: textOps},
+	"artistId":    {column: "artist_id", ops: idOps, noSort: true, nullable: true, integer: true},
+	"albumId":     {column: "album_id", ops: idOps, noSort: true, nullable: true, integer: true},
+	"song":        {column: "song", ops: textOps},
+	"releaseDate": {column: "release_date", ops: dateOps, null: "0001-01-01", nullable: true},
+	"text":        {column: "text", ops: textOps},
+	"link":        {column: "link", ops: textOps},
+}
+
+func (s Song) fieldValue(name string) string {
+	switch name {
+	case "group":
+		return s.Group
+	case "artistId":
+		return idValue(s.ArtistID)
+	case "albumId":
+		return idValue(s.AlbumID)
+	case "song":
+		return s.Song
+	case "releaseDate":
+		return s.ReleaseDate
+	case "text":
+		return s.Text
+	case "link":
+		return s.Link
+	}
+	return ""
+}
+
+func idValue(id int) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.Itoa(id)
+}
+
+// sortValue is the value of the field as the database orders it.
+func (s Song) sortValue(name string) string {
+	if value := s.fieldValue(name); value != "" {
+		return value
+	}
+	return songFields[name].null
+}
+
+func (s *Song) setFieldValue(name, value string) {
+	switch name {
+	case "group":
+		s.Group = value
+	case "song":
+		s.Song = value
+	case "releaseDate":
+		s.ReleaseDate = value
+	case "text":
+		s.Text = value
+	case "link":
+		s.Link = value
+	}
+}
+
+func FilterFields() []string {
+	fields := make([]string, 0, len(songFields))
+	for name := range songFields {
+		fields = append(fields, name)
+	}
+	slices.Sort(fields)
+	return fields
+}
+
+func SortFields() []string {
+	var fields []string
+	for _, name := range FilterFields() {
+		if !songFields[name].noSort {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+type Condition struct {
+	Field string
+	Op    Operator
+	Value string
+	// Any matches when any of its conditions does, Field, Op and Value
+	// are then unused.
+	Any Filter
+}
+
+// AnyOf is a condition that matches when any of conds does.
+func AnyOf(conds ...Condition) Condition {
+	return Condition{Any: conds}
+}
+
+type Filter []Condition
+
+type FilterError struct {
+	Field   string
+	Op      Operator
+	Allowed []string
+	// Err is set when the value does not suit the field.
+	Err error
+}
+
+func (e *FilterError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("invalid value of field %q: %v", e.Field, e.Err)
+	}
+	if e.Op == "" {
+		return fmt.Sprintf("unknown field %q, allowed fields: %s", e.Field, strings.Join(e.Allowed, ", "))
+	}
+	return fmt.Sprintf("operator %q is not supported for field %q, allowed operators: %s",
+		e.Op, e.Field, strings.Join(e.Allowed, ", "))
+}
+
+func NewCondition(name string, op Operator, value string) (Condition, error) {
+	f, ok := songFields[name]
+	if !ok {
+		return Condition{}, &FilterError{Field: name, Allowed: FilterFields()}
+	}
+	if !slices.Contains(f.ops, op) {
+		allowed := make([]string, len(f.ops))
+		for i, op := range f.ops {
+			allowed[i] = string(op)
+		}
+		return Condition{}, &FilterError{Field: name, Op: op, Allowed: allowed}
+	}
+	if f.integer {
+		// The columns are 32-bit, a larger value would fail the query.
+		n, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return Condition{}, &FilterError{Field: name, Op: op, Err: fmt.Errorf("%q is not an integer", value)}
+		}
+		value = strconv.FormatInt(n, 10)
+	}
+	return Condition{Field: name, Op: op, Value: value}, nil
+}
+
+func (f Filter) where(d *dialect, args []any) (string, []any) {
+	query := " WHERE 1=1"
+	for _, c := range f {
+		var cond string
+		cond, args = c.sql(d, args)
+		query += " AND " + cond
+	}
+	return query, args
+}
+
+func (c Condition) sql(d *dialect, args []any) (string, []any) {
+	if c.Any != nil {
+		conds := make([]string, len(c.Any))
+		for i, alt := range c.Any {
+			conds[i], args = alt.sql(d, args)
+		}
+		return "(" + strings.Join(conds, " OR ") + ")", args
+	}
+
+	f := songFields[c.Field]
+	column := f.column
+	var value any = c.Value
+
+	op, escape := "", ""
+	switch c.Op {
+	case OpEq:
+		op = "="
+	case OpNe:
+		op = "<>"
+	case OpContains:
+		op, escape = d.like, ` ESCAPE '\'`
+		value = "%" + escapeLike(c.Value) + "%"
+	case OpPrefix:
+		op, escape = d.like, ` ESCAPE '\'`
+		value = escapeLike(c.Value) + "%"
+	case OpGt:
+		op = ">"
+	case OpGte:
+		op = ">="
+	case OpLt:
+		op = "<"
+	case OpLte:
+		op = "<="
+	}
+
+	if f.integer {
+		value, _ = strconv.Atoi(c.Value)
+	}
+
+	args = append(args, value)
+	return column + " " + op + " $" + strconv.Itoa(len(args)) + escape, args
+}
+
+// match evaluates the filter the same way the SQL built by where does.
+func (f Filter) match(song Song) bool {
+	for _, c := range f {
+		if !c.match(song) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Condition) match(song Song) bool {
+	if c.Any != nil {
+		return slices.ContainsFunc(c.Any, func(alt Condition) bool { return alt.match(song) })
+	}
+
+	value := song.fieldValue(c.Field)
+	if value == "" && songFields[c.Field].nullable {
+		// NULL matches no comparison in SQL.
+		return false
+	}
+
+	switch c.Op {
+	case OpEq:
+		return value == c.Value
+	case OpNe:
+		return value != c.Value
+	case OpContains:
+		return strings.Contains(strings.ToLower(value), strings.ToLower(c.Value))
+	case OpPrefix:
+		return strings.HasPrefix(strings.ToLower(value), strings.ToLower(c.Value))
+	case OpGt:
+		return value > c.Value
+	case OpGte:
+		return value >= c.Value
+	case OpLt:
+		return value < c.Value
+	case OpLte:
+		return value <= c.Value
+	}
+	return false
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
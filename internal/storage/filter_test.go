@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFilterWhere(t *testing.T) {
+	filter := Filter{
+		{Field: "group", Op: OpEq, Value: "Muse"},
+		{Field: "song", Op: OpContains, Value: "50%_off"},
+		{Field: "releaseDate", Op: OpLt, Value: "2010-01-01"},
+		AnyOf(
+			Condition{Field: "releaseDate", Op: OpLt, Value: "2006-01-01"},
+			Condition{Field: "releaseDate", Op: OpGte, Value: "2007-01-01"},
+		),
+	}
+	tests := []struct {
+		driver    string
+		wantQuery string
+	}{
+		{"postgres", ` WHERE 1=1 AND band = $2 AND song ILIKE $3 ESCAPE '\' AND release_date < $4 AND (release_date < $5 OR release_date >= $6)`},
+		{"sqlite", ` WHERE 1=1 AND band = $2 AND song LIKE $3 ESCAPE '\' AND release_date < $4 AND (release_date < $5 OR release_date >= $6)`},
+	}
+	for _, tt := range tests {
+		d := dialects[tt.driver]
+		query, args := filter.where(&d, []any{"first"})
+
+		wantArgs := []any{"first", "Muse", `%50\%\_off%`, "2010-01-01", "2006-01-01", "2007-01-01"}
+		if query != tt.wantQuery || !reflect.DeepEqual(args, wantArgs) {
+			t.Errorf("%s: where = %q %q, want %q %q", tt.driver, query, args, tt.wantQuery, wantArgs)
+		}
+	}
+}
+
+func TestNewConditionInteger(t *testing.T) {
+	cond, err := NewCondition("artistId", OpEq, "007")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := dialects["sqlite"]
+	if query, args := cond.sql(&d, nil); query != "artist_id = $1" || !reflect.DeepEqual(args, []any{7}) {
+		t.Errorf("sql = %q %v, want artist_id = $1 [7]", query, args)
+	}
+
+	for _, value := range []string{"abc", "1.5", "9999999999"} {
+		var filterErr *FilterError
+		if _, err := NewCondition("artistId", OpEq, value); !errors.As(err, &filterErr) || filterErr.Err == nil {
+			t.Errorf("NewCondition(artistId, %q) err = %v, want a FilterError of the value", value, err)
+		}
+	}
+}
+
+func TestConditionMatchNull(t *testing.T) {
+	// A song without an artist has a NULL artist_id in SQL, which matches
+	// no comparison, not even ne.
+	song := Song{ID: 1, Group: "Muse"}
+	for _, op := range []Operator{OpEq, OpNe} {
+		if (Condition{Field: "artistId", Op: op, Value: "1"}).match(song) {
+			t.Errorf("artistId %s 1 matches a song without an artist", op)
+		}
+	}
+	song.ArtistID = 2
+	if !(Condition{Field: "artistId", Op: OpNe, Value: "1"}).match(song) {
+		t.Error("artistId ne 1 does not match a song of artist 2")
+	}
+}
@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fevse/songlib/internal/translit"
+)
+
+// DefaultMinScore is lower than the 0.3 of pg_trgm: a typo in a short
+// name costs many of its trigrams, "Muze" and "Muse" score 0.25.
+const DefaultMinScore = 0.2
+
+// Fuzzy finds songs whose band, title or both are similar to the query,
+// most similar first. Typos like "Muze" or "Supermassive Blackhole" still
+// match.
+func (r *Storage) Fuzzy(ctx context.Context, q FuzzyQuery) (*FuzzyPage, error) {
+	if !r.dialect.textSearch {
+		songs, err := r.allSongs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		page := fuzzySongs(songs, q)
+		if err := loadTags(ctx, r.conn(), matchSongs(page.Items)...); err != nil {
+			return nil, err
+		}
+		return page, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// The % operator, unlike a comparison of similarity(), uses the
+	// trigram indexes, the threshold is set for the transaction only.
+	c := conn{q: tx, dialect: &r.dialect}
+	threshold := strconv.FormatFloat(q.MinScore, 'f', -1, 64)
+	if _, err := c.Exec(ctx, `SELECT set_config('pg_trgm.similarity_threshold', $1, true)`, threshold); err != nil {
+		log.Printf("Error setting similarity threshold: %v", err)
+		return nil, err
+	}
+
+	// Keys are compared, so "Kino" matches "Кино" and "Tsoy" matches "Цой".
+	query := `
+		SELECT ` + songColumns + `,
+			similarity(band_key, $1), similarity(song_key, $1), similarity(band_key || ' ' || song_key, $1)
+		FROM songs
+		WHERE band_key % $1 OR song_key % $1 OR (band_key || ' ' || song_key) % $1
+		ORDER BY GREATEST(similarity(band_key, $1), similarity(song_key, $1), similarity(band_key || ' ' || song_key, $1)) DESC, id
+		LIMIT $2`
+	rows, err := c.Query(ctx, query, translit.Key(q.Query), q.Limit)
+	if err != nil {
+		log.Printf("Error matching songs: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &FuzzyPage{Items: []FuzzyMatch{}, Limit: q.Limit}
+	for rows.Next() {
+		var song Song
+		var scores [3]float64
+		if err := scanSong(fuzzyScanner{rows, &scores}, &song); err != nil {
+			log.Printf("Error scanning song: %v", err)
+			return nil, err
+		}
+		page.Items = append(page.Items, closest(song, scores))
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error matching songs: %v", err)
+		return nil, err
+	}
+
+	if err := loadTags(ctx, c, matchSongs(page.Items)...); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// fuzzyScanner appends the similarities of the band, the title and both
+// to the song columns scanned by scanSong.
+type fuzzyScanner struct {
+	row    scanner
+	scores *[3]float64
+}
+
+func (s fuzzyScanner) Scan(dest ...any) error {
+	return s.row.Scan(append(dest, &s.scores[0], &s.scores[1], &s.scores[2])...)
+}
+
+func matchSongs(matches []FuzzyMatch) []*Song {
+	songs := make([]*Song, len(matches))
+	for i := range matches {
+		songs[i] = &matches[i].Song
+	}
+	return songs
+}
+
+// closest builds the match of the song from the similarities of its band,
+// title and both, the title wins a tie.
+func closest(song Song, scores [3]float64) FuzzyMatch {
+	band, title, both := scores[0], scores[1], scores[2]
+	switch {
+	case title >= band && title >= both:
+		return FuzzyMatch{Song: song, Score: title, Match: song.Song}
+	case band >= both:
+		return FuzzyMatch{Song: song, Score: band, Match: song.Group}
+	}
+	return FuzzyMatch{Song: song, Score: both, Match: song.Group + " " + song.Song}
+}
+
+// trigrams returns the trigrams of the key of s the way pg_trgm builds
+// them: every word padded with two spaces in front and one behind.
+func trigrams(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(translit.Key(s)) {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			set[string(runes[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// similarity is the pg_trgm similarity of a and b: shared trigrams over
+// all trigrams of both.
+func similarity(a, b map[string]bool) float64 {
+	var shared int
+	for t := range a {
+		if b[t] {
+			shared++
+		}
+	}
+	if all := len(a) + len(b) - shared; all > 0 {
+		return float64(shared) / float64(all)
+	}
+	return 0
+}
+
+// fuzzySongs is the fuzzy match of SQLite and MemoryStorage, it orders
+// the songs like the Postgres query.
+func fuzzySongs(songs []Song, q FuzzyQuery) *FuzzyPage {
+	query := trigrams(q.Query)
+
+	var matches []FuzzyMatch
+	for _, song := range songs {
+		m := closest(song, [3]float64{
+			similarity(trigrams(song.Group), query),
+			similarity(trigrams(song.Song), query),
+			similarity(trigrams(song.Group+" "+song.Song), query),
+		})
+		// The % operator of pg_trgm is strict and compares reals.
+		if float32(m.Score) > float32(q.MinScore) {
+			matches = append(matches, m)
+		}
+	}
+	slices.SortFunc(matches, func(a, b FuzzyMatch) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	page := &FuzzyPage{Items: []FuzzyMatch{}, Limit: q.Limit}
+	page.Items = append(page.Items, matches[:min(q.Limit, len(matches))]...)
+	return page
+}
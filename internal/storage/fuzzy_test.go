@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"Muse", "muse", 1},
+		{"Muze", "Muse", 0.25},
+		{"Muse", "Kino", 0},
+		{"", "Muse", 0},
+	}
+	for _, tt := range tests {
+		if got := similarity(trigrams(tt.a), trigrams(tt.b)); got != tt.want {
+			t.Errorf("similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFuzzy(t *testing.T) {
+	forEachRepository(t, testFuzzy)
+}
+
+func testFuzzy(t *testing.T, newRepo func(...*Song) Repository) {
+	ctx := context.Background()
+	repo := newRepo(
+		&Song{Group: "Muse", Song: "Supermassive Black Hole"},
+		&Song{Group: "Kino", Song: "Kukushka"},
+		&Song{Group: "Muse", Song: "Uprising"},
+		&Song{Group: "Цой", Song: "Звезда"},
+	)
+
+	tests := []struct {
+		q         FuzzyQuery
+		want      []int
+		wantMatch string
+	}{
+		{FuzzyQuery{Query: "Muze", MinScore: DefaultMinScore}, []int{1, 3}, "Muse"},
+		{FuzzyQuery{Query: "Supermassive Blackhole", MinScore: DefaultMinScore}, []int{1}, "Supermassive Black Hole"},
+		{FuzzyQuery{Query: "kino kukushka", MinScore: DefaultMinScore}, []int{2}, "Kino Kukushka"},
+		{FuzzyQuery{Query: "Tsoy", MinScore: DefaultMinScore}, []int{4}, "Цой"},
+		{FuzzyQuery{Query: "Muze", MinScore: 0.3}, []int{}, ""},
+		// Like the % operator of pg_trgm, a match must exceed minScore.
+		{FuzzyQuery{Query: "Muze", MinScore: 0.25}, []int{}, ""},
+		{FuzzyQuery{Query: "Muze", MinScore: 0.24}, []int{1, 3}, "Muse"},
+		{FuzzyQuery{Query: "Muse", MinScore: DefaultMinScore, Limit: 1}, []int{1}, "Muse"},
+	}
+	for _, tt := range tests {
+		if tt.q.Limit == 0 {
+			tt.q.Limit = 10
+		}
+		page, err := repo.Fuzzy(ctx, tt.q)
+		if err != nil {
+			t.Errorf("Fuzzy(%+v): %v", tt.q, err)
+			continue
+		}
+		got := []int{}
+		for _, m := range page.Items {
+			got = append(got, m.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Fuzzy(%+v) = %v, want %v", tt.q, got, tt.want)
+			continue
+		}
+		if len(page.Items) > 0 && page.Items[0].Match != tt.wantMatch {
+			t.Errorf("Fuzzy(%+v) match = %q, want %q", tt.q, page.Items[0].Match, tt.wantMatch)
+		}
+	}
+}
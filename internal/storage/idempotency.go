@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+)
+
+// ReserveKey saves the key of a request before it is handled, until
+// rec.ExpiresAt. If the key is already taken, the record saved with it is
+// returned instead and nothing is reserved. Expired keys are removed
+// first.
+func (r *Storage) ReserveKey(ctx context.Context, rec IdempotencyRecord) (*IdempotencyRecord, error) {
+	c := r.conn()
+	if _, err := c.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, time.Now().UTC()); err != nil {
+		log.Printf("Error removing expired idempotency keys: %v", err)
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO idempotency_keys (idempotency_key, request_hash, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (idempotency_key) DO NOTHING`
+	res, err := c.Exec(ctx, query, rec.Key, rec.RequestHash, rec.ExpiresAt.UTC())
+	if err != nil {
+		log.Printf("Error reserving idempotency key: %v", err)
+		return nil, err
+	}
+	if err := checkAffected(res); err == nil {
+		return nil, nil
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	saved := IdempotencyRecord{Key: rec.Key}
+	query = `
+		SELECT request_hash, status, content_type, body
+		FROM idempotency_keys
+		WHERE idempotency_key = $1`
+	err = c.QueryRow(ctx, query, rec.Key).Scan(&saved.RequestHash, &saved.Status, &saved.ContentType, &saved.Body)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The first request failed and released the key just now, it is
+		// reported as still in progress and the client retries.
+		saved.RequestHash = rec.RequestHash
+		return &saved, nil
+	} else if err != nil {
+		log.Printf("Error getting idempotency key: %v", err)
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// SaveResponse saves the response to the request that reserved the key,
+// the key then expires at rec.ExpiresAt instead of the end of its lease.
+func (r *Storage) SaveResponse(ctx context.Context, rec IdempotencyRecord) error {
+	query := `
+		UPDATE idempotency_keys SET status = $1, content_type = $2, body = $3, expires_at = $4
+		WHERE idempotency_key = $5`
+	res, err := r.conn().Exec(ctx, query, rec.Status, rec.ContentType, rec.Body, rec.ExpiresAt.UTC(), rec.Key)
+	if err != nil {
+		log.Printf("Error saving idempotent response: %v", err)
+		return err
+	}
+	return checkAffected(res)
+}
+
+// ReleaseKey removes a reserved key without a response, so that the
+// request can be retried.
+func (r *Storage) ReleaseKey(ctx context.Context, key string) error {
+	query := `DELETE FROM idempotency_keys WHERE idempotency_key = $1 AND status = 0`
+	if _, err := r.conn().Exec(ctx, query, key); err != nil {
+		log.Printf("Error releasing idempotency key: %v", err)
+		return err
+	}
+	return nil
+}
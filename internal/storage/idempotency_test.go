@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	forEachRepository(t, testIdempotencyKeys)
+}
+
+func testIdempotencyKeys(t *testing.T, newRepo func(...*Song) Repository) {
+	ctx := context.Background()
+	repo := newRepo()
+	rec := IdempotencyRecord{Key: "k1", RequestHash: "h1", ExpiresAt: time.Now().Add(time.Hour)}
+
+	reserve := func(rec IdempotencyRecord) *IdempotencyRecord {
+		t.Helper()
+		saved, err := repo.ReserveKey(ctx, rec)
+		if err != nil {
+			t.Fatalf("ReserveKey(%s): %v", rec.Key, err)
+		}
+		return saved
+	}
+
+	if saved := reserve(rec); saved != nil {
+		t.Fatalf("ReserveKey of a new key = %+v, want nil", saved)
+	}
+	// The key is in progress until a response is saved.
+	if saved := reserve(rec); saved == nil || saved.RequestHash != "h1" || saved.Status != 0 {
+		t.Errorf("ReserveKey of a reserved key = %+v, want it in progress", saved)
+	}
+
+	rec.Status, rec.ContentType, rec.Body = 201, "application/json", `{"id":1}`
+	if err := repo.SaveResponse(ctx, rec); err != nil {
+		t.Fatal(err)
+	}
+	// A saved response is not released.
+	if err := repo.ReleaseKey(ctx, rec.Key); err != nil {
+		t.Fatal(err)
+	}
+	want := IdempotencyRecord{Key: "k1", RequestHash: "h1", Status: 201, ContentType: "application/json", Body: `{"id":1}`}
+	saved := reserve(IdempotencyRecord{Key: "k1", RequestHash: "h2", ExpiresAt: rec.ExpiresAt})
+	if saved == nil {
+		t.Fatal("ReserveKey of a saved key = nil, want the response")
+	}
+	// Only the memory storage reads the expiry back.
+	saved.ExpiresAt = time.Time{}
+	if *saved != want {
+		t.Errorf("ReserveKey of a saved key = %+v, want %+v", *saved, want)
+	}
+
+	if err := repo.SaveResponse(ctx, IdempotencyRecord{Key: "k9", Status: 200}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SaveResponse of a missing key err = %v, want ErrNotFound", err)
+	}
+
+	// A released key can be reserved again.
+	reserve(IdempotencyRecord{Key: "k2", RequestHash: "h1", ExpiresAt: rec.ExpiresAt})
+	if err := repo.ReleaseKey(ctx, "k2"); err != nil {
+		t.Fatal(err)
+	}
+	if saved := reserve(IdempotencyRecord{Key: "k2", RequestHash: "h1", ExpiresAt: rec.ExpiresAt}); saved != nil {
+		t.Errorf("ReserveKey of a released key = %+v, want nil", saved)
+	}
+
+	// So can an expired one.
+	reserve(IdempotencyRecord{Key: "k3", RequestHash: "h1", ExpiresAt: time.Now().Add(-time.Second)})
+	if saved := reserve(IdempotencyRecord{Key: "k3", RequestHash: "h2", ExpiresAt: rec.ExpiresAt}); saved != nil {
+		t.Errorf("ReserveKey of an expired key = %+v, want nil", saved)
+	}
+
+	// A saved response expires at its own time instead of the lease.
+	reserve(IdempotencyRecord{Key: "k4", RequestHash: "h1", ExpiresAt: rec.ExpiresAt})
+	if err := repo.SaveResponse(ctx, IdempotencyRecord{Key: "k4", Status: 201, ExpiresAt: time.Now().Add(-time.Second)}); err != nil {
+		t.Fatal(err)
+	}
+	if saved := reserve(IdempotencyRecord{Key: "k4", RequestHash: "h2", ExpiresAt: rec.ExpiresAt}); saved != nil {
+		t.Errorf("ReserveKey of an expired response = %+v, want nil", saved)
+	}
+}
@@ -0,0 +1,256 @@
+package storage
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/fevse/songlib/internal/translit"
+)
+
+// MemoryStorage is a SongRepository that keeps songs in memory. It is
+// meant for local development and tests, data is lost on restart.
+type MemoryStorage struct {
+	mu           sync.RWMutex
+	songs        map[int]Song
+	nextID       int
+	artists      map[int]Artist
+	nextArtistID int
+	albums       map[int]Album
+	nextAlbumID  int
+	// tags maps tag names to their kinds.
+	tags map[string]string
+	// playlists hold names only, entries are kept in order apart.
+	playlists       map[int]Playlist
+	playlistEntries map[int][]entryPosition
+	nextPlaylistID  int
+	nextEntryID     int
+	// redirects maps ids of merged duplicates to their songs.
+	redirects       map[int]int
+	idempotencyKeys map[string]IdempotencyRecord
+	// unique mirrors songs_keys_key, see SetUniqueSongs.
+	unique bool
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		songs:           make(map[int]Song),
+		nextID:          1,
+		artists:         make(map[int]Artist),
+		nextArtistID:    1,
+		albums:          make(map[int]Album),
+		nextAlbumID:     1,
+		tags:            make(map[string]string),
+		playlists:       make(map[int]Playlist),
+		playlistEntries: make(map[int][]entryPosition),
+		nextPlaylistID:  1,
+		nextEntryID:     1,
+		redirects:       make(map[int]int),
+		idempotencyKeys: make(map[string]IdempotencyRecord),
+	}
+}
+
+func (m *MemoryStorage) Create(ctx context.Context, song *Song) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	artistID := m.nextArtistID
+	if err := m.resolveArtist(song); err != nil {
+		return err
+	}
+	if err := m.uniqueSong(0, *song, artistID); err != nil {
+		return err
+	}
+
+	song.ID = m.nextID
+	m.nextID++
+	m.songs[song.ID] = *song
+	return nil
+}
+
+func (m *MemoryStorage) GetByID(ctx context.Context, id int) (*Song, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	song, ok := m.songs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &song, nil
+}
+
+func (m *MemoryStorage) Update(ctx context.Context, song *Song) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.songs[song.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	artistID := m.nextArtistID
+	if err := m.resolveArtist(song); err != nil {
+		return err
+	}
+	if err := m.uniqueSong(song.ID, *song, artistID); err != nil {
+		return err
+	}
+	song.AlbumID, song.DiscNumber, song.TrackNumber = current.AlbumID, current.DiscNumber, current.TrackNumber
+	song.Tags = current.Tags
+	m.songs[song.ID] = *song
+	return nil
+}
+
+func (m *MemoryStorage) UpdateFunc(ctx context.Context, id int, fn func(song *Song) error) (*Song, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	song, ok := m.songs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	current := song
+	if err := fn(&song); err != nil {
+		return nil, err
+	}
+	song.ID = id
+	song.AlbumID, song.DiscNumber, song.TrackNumber = current.AlbumID, current.DiscNumber, current.TrackNumber
+	song.Tags = current.Tags
+	artistID := m.nextArtistID
+	if err := m.resolveArtist(&song); err != nil {
+		return nil, err
+	}
+	if err := m.uniqueSong(id, song, artistID); err != nil {
+		return nil, err
+	}
+
+	m.songs[id] = song
+	return &song, nil
+}
+
+// SetUniqueSongs mirrors Storage.SetUniqueSongs.
+func (m *MemoryStorage) SetUniqueSongs(ctx context.Context, unique bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if unique {
+		for _, song := range m.songs {
+			if _, ok := m.songByName(song.Group, song.Song, song.ID); ok {
+				return ErrDuplicateSongs
+			}
+		}
+	}
+	m.unique = unique
+	return nil
+}
+
+// uniqueSong mirrors songs_keys_key, when it is set no song but the one
+// with the id may have the band and title keys of the song. On conflict
+// the artist resolveArtist created for the song, with an id from
+// newArtistID on, is removed like by a rolled back transaction. m.mu must
+// be held.
+func (m *MemoryStorage) uniqueSong(id int, song Song, newArtistID int) error {
+	if !m.unique {
+		return nil
+	}
+	if _, ok := m.songByName(song.Group, song.Song, id); !ok {
+		return nil
+	}
+	if song.ArtistID >= newArtistID {
+		delete(m.artists, song.ArtistID)
+	}
+	return &ConflictError{Constraint: "songs_keys_key"}
+}
+
+// songByName finds the oldest song other than except with the band and
+// title keys of group and title, m.mu must be held. Empty keys match no
+// song, see Storage.GetByName.
+func (m *MemoryStorage) songByName(group, title string, except int) (Song, bool) {
+	band, key := translit.Key(group), translit.Key(title)
+	if band == "" || key == "" {
+		return Song{}, false
+	}
+
+	found := Song{}
+	for _, song := range m.songs {
+		if song.ID != except && translit.Key(song.Group) == band && translit.Key(song.Song) == key &&
+			(found.ID == 0 || song.ID < found.ID) {
+			found = song
+		}
+	}
+	return found, found.ID != 0
+}
+
+func (m *MemoryStorage) GetByName(ctx context.Context, group, title string) (*Song, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	song, ok := m.songByName(group, title, 0)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &song, nil
+}
+
+func (m *MemoryStorage) Delete(ctx context.Context, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.songs[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.songs, id)
+	// Entries and redirects of the song go with it, like ON DELETE CASCADE.
+	for playlistID, entries := range m.playlistEntries {
+		m.playlistEntries[playlistID] = slices.DeleteFunc(entries, func(e entryPosition) bool {
+			return e.songID == id
+		})
+	}
+	maps.DeleteFunc(m.redirects, func(_, songID int) bool { return songID == id })
+	return nil
+}
+
+// GetList mirrors Storage.GetList: the same filter, order, keyset and
+// offset pagination rules apply.
+func (m *MemoryStorage) GetList(ctx context.Context, q ListQuery) (*SongPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var c *cursor
+	if q.Cursor != "" {
+		var err error
+		if c, err = decodeCursor(q.Cursor, q.Sort); err != nil {
+			return nil, err
+		}
+	}
+
+	m.mu.RLock()
+	var songs []Song
+	for _, song := range m.songs {
+		if q.match(song) {
+			songs = append(songs, song)
+		}
+	}
+	m.mu.RUnlock()
+
+	slices.SortFunc(songs, q.Sort.compare)
+
+	page := &SongPage{Items: []Song{}, Total: len(songs), Limit: q.Limit, Offset: q.Offset, Cursor: q.Cursor}
+	if c != nil {
+		songs = slices.DeleteFunc(songs, func(song Song) bool {
+			return !c.follows(q.Sort, song)
+		})
+	} else {
+		songs = songs[min(q.Offset, len(songs)):]
+	}
+
+	if len(songs) > q.Limit {
+		page.Items = songs[:q.Limit]
+		page.NextCursor = encodeCursor(q.Sort, page.Items[q.Limit-1])
+	} else if len(songs) > 0 {
+		page.Items = songs
+	}
+	return page, nil
+}
@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+func (m *MemoryStorage) CreateAlbum(ctx context.Context, album *Album) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.albumArtist(album); err != nil {
+		return err
+	}
+
+	album.ID = m.nextAlbumID
+	m.nextAlbumID++
+	album.Tracks = nil
+	m.albums[album.ID] = *album
+	return nil
+}
+
+// albumArtist mirrors the SQL albumArtist, m.mu must be held.
+func (m *MemoryStorage) albumArtist(album *Album) error {
+	artist, ok := m.artists[album.ArtistID]
+	if !ok {
+		return ErrUnknownArtist
+	}
+	album.Artist = artist.Name
+	return nil
+}
+
+func (m *MemoryStorage) GetAlbum(ctx context.Context, id int) (*Album, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	album, ok := m.albums[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	m.albumArtist(&album)
+
+	album.Tracks = []Song{}
+	for _, song := range m.songs {
+		if song.AlbumID == id {
+			album.Tracks = append(album.Tracks, song)
+		}
+	}
+	slices.SortFunc(album.Tracks, func(a, b Song) int {
+		if c := cmp.Compare(a.DiscNumber, b.DiscNumber); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.TrackNumber, b.TrackNumber); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return &album, nil
+}
+
+func (m *MemoryStorage) UpdateAlbum(ctx context.Context, album *Album) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.albumArtist(album); err != nil {
+		return err
+	}
+	if _, ok := m.albums[album.ID]; !ok {
+		return ErrNotFound
+	}
+	album.Tracks = nil
+	m.albums[album.ID] = *album
+	return nil
+}
+
+func (m *MemoryStorage) DeleteAlbum(ctx context.Context, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.albums[id]; !ok {
+		return ErrNotFound
+	}
+	m.clearTracks(id)
+	delete(m.albums, id)
+	return nil
+}
+
+func (m *MemoryStorage) clearTracks(albumID int) {
+	for id, song := range m.songs {
+		if song.AlbumID == albumID {
+			song.AlbumID, song.DiscNumber, song.TrackNumber = 0, 0, 0
+			m.songs[id] = song
+		}
+	}
+}
+
+func (m *MemoryStorage) SetTracks(ctx context.Context, albumID int, tracks []Track) (*Album, error) {
+	if err := m.setTracks(albumID, tracks); err != nil {
+		return nil, err
+	}
+	return m.GetAlbum(ctx, albumID)
+}
+
+func (m *MemoryStorage) setTracks(albumID int, tracks []Track) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	album, ok := m.albums[albumID]
+	if !ok {
+		return ErrNotFound
+	}
+	// Check every song first, nothing changes if one is missing.
+	for _, track := range tracks {
+		if _, ok := m.songs[track.SongID]; !ok {
+			return fmt.Errorf("%w: %d", ErrUnknownSong, track.SongID)
+		}
+	}
+
+	m.clearTracks(albumID)
+	for _, track := range tracks {
+		song := m.songs[track.SongID]
+		song.AlbumID, song.DiscNumber, song.TrackNumber = albumID, track.DiscNumber, track.TrackNumber
+		if song.ReleaseDate == "" {
+			song.ReleaseDate, song.ReleaseDatePrecision = album.ReleaseDate, album.ReleaseDatePrecision
+		}
+		m.songs[song.ID] = song
+	}
+	return nil
+}
+
+func (m *MemoryStorage) RemoveTrack(ctx context.Context, albumID, songID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	song, ok := m.songs[songID]
+	if !ok || song.AlbumID != albumID {
+		return ErrNotFound
+	}
+	song.AlbumID, song.DiscNumber, song.TrackNumber = 0, 0, 0
+	m.songs[songID] = song
+	return nil
+}
+
+func (m *MemoryStorage) GetAlbums(ctx context.Context, q AlbumQuery) (*AlbumPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	var albums []Album
+	for _, album := range m.albums {
+		if q.ArtistID != 0 && album.ArtistID != q.ArtistID {
+			continue
+		}
+		if !strings.Contains(strings.ToLower(album.Title), strings.ToLower(q.Title)) {
+			continue
+		}
+		m.albumArtist(&album)
+		albums = append(albums, album)
+	}
+	m.mu.RUnlock()
+
+	slices.SortFunc(albums, func(a, b Album) int {
+		if c := strings.Compare(nameKey(a.Artist), nameKey(b.Artist)); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.ReleaseDate, b.ReleaseDate); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	page := &AlbumPage{Items: []Album{}, Total: len(albums), Limit: q.Limit, Offset: q.Offset}
+	albums = albums[min(q.Offset, len(albums)):]
+	page.Items = append(page.Items, albums[:min(q.Limit, len(albums))]...)
+	return page, nil
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+func (m *MemoryStorage) CreateArtist(ctx context.Context, artist *Artist) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	artist.Name = normalizeName(artist.Name)
+	if _, ok := m.artistByKey(nameKey(artist.Name)); ok {
+		return &ConflictError{Constraint: "artists_name_key_key"}
+	}
+
+	artist.ID = m.nextArtistID
+	m.nextArtistID++
+	m.artists[artist.ID] = *artist
+	return nil
+}
+
+func (m *MemoryStorage) GetArtist(ctx context.Context, id int) (*Artist, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	artist, ok := m.artists[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &artist, nil
+}
+
+func (m *MemoryStorage) artistByKey(key string) (Artist, bool) {
+	for _, artist := range m.artists {
+		if nameKey(artist.Name) == key {
+			return artist, true
+		}
+	}
+	return Artist{}, false
+}
+
+// resolveArtist mirrors the SQL resolveArtist, m.mu must be held.
+func (m *MemoryStorage) resolveArtist(song *Song) error {
+	if song.ArtistID == 0 {
+		artist, ok := m.artistByKey(nameKey(song.Group))
+		if !ok {
+			artist = Artist{ID: m.nextArtistID, Name: normalizeName(song.Group)}
+			m.nextArtistID++
+			m.artists[artist.ID] = artist
+		}
+		song.ArtistID, song.Group = artist.ID, artist.Name
+		return nil
+	}
+
+	artist, ok := m.artists[song.ArtistID]
+	if !ok {
+		return ErrUnknownArtist
+	}
+	return linkArtist(song, &artist)
+}
+
+func (m *MemoryStorage) UpdateArtist(ctx context.Context, artist *Artist) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.artists[artist.ID]; !ok {
+		return ErrNotFound
+	}
+	artist.Name = normalizeName(artist.Name)
+	if other, ok := m.artistByKey(nameKey(artist.Name)); ok && other.ID != artist.ID {
+		return &ConflictError{Constraint: "artists_name_key_key"}
+	}
+
+	for _, song := range m.songs {
+		if !m.unique || song.ArtistID != artist.ID {
+			continue
+		}
+		if other, ok := m.songByName(artist.Name, song.Song, song.ID); ok && other.ArtistID != artist.ID {
+			return &ConflictError{Constraint: "songs_keys_key"}
+		}
+	}
+
+	m.artists[artist.ID] = *artist
+	for id, song := range m.songs {
+		if song.ArtistID == artist.ID {
+			song.Group = artist.Name
+			m.songs[id] = song
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStorage) DeleteArtist(ctx context.Context, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.artists[id]; !ok {
+		return ErrNotFound
+	}
+	for _, song := range m.songs {
+		if song.ArtistID == id {
+			return &ConflictError{Constraint: "songs_artist_id_fkey"}
+		}
+	}
+	for _, album := range m.albums {
+		if album.ArtistID == id {
+			return &ConflictError{Constraint: "albums_artist_id_fkey"}
+		}
+	}
+	delete(m.artists, id)
+	return nil
+}
+
+func (m *MemoryStorage) GetArtists(ctx context.Context, q ArtistQuery) (*ArtistPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	var artists []Artist
+	for _, artist := range m.artists {
+		if strings.Contains(nameKey(artist.Name), nameKey(q.Name)) {
+			artists = append(artists, artist)
+		}
+	}
+	m.mu.RUnlock()
+
+	slices.SortFunc(artists, func(a, b Artist) int {
+		if c := strings.Compare(nameKey(a.Name), nameKey(b.Name)); c != 0 {
+			return c
+		}
+		return a.ID - b.ID
+	})
+
+	page := &ArtistPage{Items: []Artist{}, Total: len(artists), Limit: q.Limit, Offset: q.Offset}
+	artists = artists[min(q.Offset, len(artists)):]
+	page.Items = append(page.Items, artists[:min(q.Limit, len(artists))]...)
+	return page, nil
+}
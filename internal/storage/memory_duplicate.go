@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+func (m *MemoryStorage) Duplicates(ctx context.Context, q DuplicateQuery) (*DuplicatePage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	songs := slices.Collect(maps.Values(m.songs))
+	m.mu.RUnlock()
+
+	return duplicateGroups(songs, q), nil
+}
+
+func (m *MemoryStorage) Merge(ctx context.Context, id int, duplicates []int, force bool) (*Song, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	song, ok := m.songs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	for _, dupID := range duplicates {
+		dup, ok := m.songs[dupID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %d", ErrUnknownSong, dupID)
+		}
+		if !force && !sameKeys(&song, &dup) {
+			return nil, fmt.Errorf("%w: %d", ErrNotDuplicate, dupID)
+		}
+	}
+
+	song.Tags = slices.Clone(song.Tags)
+	for _, dupID := range duplicates {
+		dup := m.songs[dupID]
+		foldSong(&song, &dup)
+		for _, tag := range dup.Tags {
+			if !slices.Contains(song.Tags, tag) {
+				song.Tags = append(song.Tags, tag)
+			}
+		}
+		for _, entries := range m.playlistEntries {
+			for i := range entries {
+				if entries[i].songID == dupID {
+					entries[i].songID = id
+				}
+			}
+		}
+		for oldID, songID := range m.redirects {
+			if songID == dupID {
+				m.redirects[oldID] = id
+			}
+		}
+		m.redirects[dupID] = id
+		delete(m.songs, dupID)
+	}
+	slices.Sort(song.Tags)
+
+	m.songs[id] = song
+	return &song, nil
+}
+
+func (m *MemoryStorage) Redirect(ctx context.Context, id int) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	songID, ok := m.redirects[id]
+	if !ok {
+		return 0, ErrNotFound
+	}
+	return songID, nil
+}
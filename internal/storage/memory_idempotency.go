@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"maps"
+	"time"
+)
+
+func (m *MemoryStorage) ReserveKey(ctx context.Context, rec IdempotencyRecord) (*IdempotencyRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	maps.DeleteFunc(m.idempotencyKeys, func(_ string, saved IdempotencyRecord) bool {
+		return !saved.ExpiresAt.After(now)
+	})
+
+	if saved, ok := m.idempotencyKeys[rec.Key]; ok {
+		return &saved, nil
+	}
+	m.idempotencyKeys[rec.Key] = IdempotencyRecord{Key: rec.Key, RequestHash: rec.RequestHash, ExpiresAt: rec.ExpiresAt}
+	return nil, nil
+}
+
+func (m *MemoryStorage) SaveResponse(ctx context.Context, rec IdempotencyRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	saved, ok := m.idempotencyKeys[rec.Key]
+	if !ok {
+		return ErrNotFound
+	}
+	saved.Status, saved.ContentType, saved.Body = rec.Status, rec.ContentType, rec.Body
+	saved.ExpiresAt = rec.ExpiresAt
+	m.idempotencyKeys[rec.Key] = saved
+	return nil
+}
+
+func (m *MemoryStorage) ReleaseKey(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if saved, ok := m.idempotencyKeys[key]; ok && saved.Status == 0 {
+		delete(m.idempotencyKeys, key)
+	}
+	return nil
+}
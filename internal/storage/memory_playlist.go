@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+func (m *MemoryStorage) CreatePlaylist(ctx context.Context, playlist *Playlist) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	playlist.ID = m.nextPlaylistID
+	m.nextPlaylistID++
+	playlist.Entries = nil
+	m.playlists[playlist.ID] = *playlist
+	return nil
+}
+
+func (m *MemoryStorage) GetPlaylist(ctx context.Context, id int) (*Playlist, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	playlist, ok := m.playlists[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	entries := m.playlistEntries[id]
+	playlist.Entries = []PlaylistEntry{}
+	for _, e := range entries {
+		if song, ok := m.songs[e.songID]; ok {
+			playlist.Entries = append(playlist.Entries, PlaylistEntry{ID: e.id, Position: len(playlist.Entries) + 1, Song: song})
+		}
+	}
+	return &playlist, nil
+}
+
+func (m *MemoryStorage) UpdatePlaylist(ctx context.Context, playlist *Playlist) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.playlists[playlist.ID]; !ok {
+		return ErrNotFound
+	}
+	playlist.Entries = nil
+	m.playlists[playlist.ID] = *playlist
+	return nil
+}
+
+func (m *MemoryStorage) DeletePlaylist(ctx context.Context, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.playlists[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.playlists, id)
+	delete(m.playlistEntries, id)
+	return nil
+}
+
+func (m *MemoryStorage) GetPlaylists(ctx context.Context, q PlaylistQuery) (*PlaylistPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	var playlists []Playlist
+	for _, playlist := range m.playlists {
+		if strings.Contains(strings.ToLower(playlist.Name), strings.ToLower(q.Name)) {
+			playlists = append(playlists, playlist)
+		}
+	}
+	m.mu.RUnlock()
+
+	slices.SortFunc(playlists, func(a, b Playlist) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	page := &PlaylistPage{Items: []Playlist{}, Total: len(playlists), Limit: q.Limit, Offset: q.Offset}
+	playlists = playlists[min(q.Offset, len(playlists)):]
+	page.Items = append(page.Items, playlists[:min(q.Limit, len(playlists))]...)
+	return page, nil
+}
+
+// editPlaylist mirrors Storage.editPlaylist, the entries edit returns
+// replace those of the playlist.
+func (m *MemoryStorage) editPlaylist(playlistID int, edit func(entries []entryPosition) ([]entryPosition, error)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.playlists[playlistID]; !ok {
+		return ErrNotFound
+	}
+	entries, err := edit(slices.Clone(m.playlistEntries[playlistID]))
+	if err != nil {
+		return err
+	}
+	m.playlistEntries[playlistID] = entries
+	return nil
+}
+
+func (m *MemoryStorage) InsertEntry(ctx context.Context, playlistID int, insert PlaylistInsert) (*Playlist, error) {
+	err := m.editPlaylist(playlistID, func(entries []entryPosition) ([]entryPosition, error) {
+		at, err := insertIndex(insert.Position, len(entries))
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := m.songs[insert.SongID]; !ok {
+			return nil, fmt.Errorf("%w: %d", ErrUnknownSong, insert.SongID)
+		}
+
+		e := entryPosition{id: m.nextEntryID, songID: insert.SongID}
+		m.nextEntryID++
+		return slices.Insert(entries, at, e), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m.GetPlaylist(ctx, playlistID)
+}
+
+func (m *MemoryStorage) MoveEntry(ctx context.Context, playlistID, entryID int, move PlaylistMove) (*Playlist, error) {
+	err := m.editPlaylist(playlistID, func(entries []entryPosition) ([]entryPosition, error) {
+		return moveEntry(entries, entryID, move.Position)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m.GetPlaylist(ctx, playlistID)
+}
+
+func (m *MemoryStorage) RemoveEntry(ctx context.Context, playlistID, entryID int) error {
+	return m.editPlaylist(playlistID, func(entries []entryPosition) ([]entryPosition, error) {
+		i := slices.IndexFunc(entries, func(e entryPosition) bool { return e.id == entryID })
+		if i < 0 {
+			return nil, ErrNotFound
+		}
+		return slices.Delete(entries, i, i+1), nil
+	})
+}
@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"context"
+	"maps"
+	"slices"
+)
+
+func (m *MemoryStorage) Search(ctx context.Context, q SearchQuery) (*SearchPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	songs := slices.Collect(maps.Values(m.songs))
+	m.mu.RUnlock()
+
+	return searchSongs(songs, q), nil
+}
+
+func (m *MemoryStorage) Fuzzy(ctx context.Context, q FuzzyQuery) (*FuzzyPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	songs := slices.Collect(maps.Values(m.songs))
+	m.mu.RUnlock()
+
+	return fuzzySongs(songs, q), nil
+}
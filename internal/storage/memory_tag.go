@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+)
+
+func (m *MemoryStorage) AddTags(ctx context.Context, songID int, tags []Tag) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	song, ok := m.songs[songID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	song.Tags = slices.Clone(song.Tags)
+	for _, tag := range tags {
+		name := TagName(tag.Name)
+		if kind, ok := m.tags[name]; !ok || kind == "" {
+			m.tags[name] = tag.Kind
+		}
+		if !slices.Contains(song.Tags, name) {
+			song.Tags = append(song.Tags, name)
+		}
+	}
+	slices.Sort(song.Tags)
+
+	m.songs[songID] = song
+	return nil
+}
+
+func (m *MemoryStorage) RemoveTags(ctx context.Context, songID int, names []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	song, ok := m.songs[songID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	song.Tags = slices.DeleteFunc(slices.Clone(song.Tags), func(tag string) bool {
+		return slices.ContainsFunc(names, func(name string) bool { return TagName(name) == tag })
+	})
+	if len(song.Tags) == 0 {
+		song.Tags = nil
+	}
+
+	m.songs[songID] = song
+	return nil
+}
+
+func (m *MemoryStorage) Facets(ctx context.Context, q ListQuery, kind string) (*Facets, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	facets := &Facets{Tags: []TagCount{}}
+	counts := make(map[string]int)
+	for _, song := range m.songs {
+		if !q.match(song) {
+			continue
+		}
+		facets.Total++
+		for _, tag := range song.Tags {
+			if kind == "" || m.tags[tag] == kind {
+				counts[tag]++
+			}
+		}
+	}
+	for name, count := range counts {
+		facets.Tags = append(facets.Tags, TagCount{Name: name, Kind: m.tags[name], Count: count})
+	}
+	m.mu.RUnlock()
+
+	slices.SortFunc(facets.Tags, func(a, b TagCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return facets, nil
+}
@@ -1,12 +1,262 @@
 package storage
 
+import "time"
+
 type Song struct {
+	ID                   int    `json:"id"`
+	Group                string `json:"group"`
+	ArtistID             int    `json:"artistId"`
+	Song                 string `json:"song"`
+	ReleaseDate          string `json:"releaseDate" example:"2006-07-16"`
+	ReleaseDatePrecision string `json:"releaseDatePrecision,omitempty" enums:"day,month,year"`
+	Text                 string `json:"text"`
+	Link                 string `json:"link"`
+	// Album fields are managed with the album tracks endpoints.
+	AlbumID     int `json:"albumId,omitempty" readonly:"true"`
+	DiscNumber  int `json:"discNumber,omitempty" readonly:"true"`
+	TrackNumber int `json:"trackNumber,omitempty" readonly:"true"`
+	// Tags are managed with the song tags endpoints.
+	Tags []string `json:"tags,omitempty" readonly:"true"`
+}
+
+type ListQuery struct {
+	Filter Filter
+	// Tags narrows the list down to songs with all or any of the tags,
+	// depending on TagMode.
+	Tags    []string
+	TagMode TagMode
+	Sort    Sort
+	Limit   int
+	Offset  int
+	Cursor  string
+}
+
+type SongPage struct {
+	Items      []Song `json:"items"`
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	Cursor     string `json:"cursor,omitempty"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	Next       string `json:"next,omitempty"`
+}
+
+type Artist struct {
 	ID          int    `json:"id"`
-	Group       string `json:"group"`
-	Song        string `json:"song"`
-	ReleaseDate string `json:"releaseDate"`
-	Text        string `json:"text"`
-	Link        string `json:"link"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type ArtistQuery struct {
+	// Name matches artists whose name contains it, case-insensitively.
+	Name   string
+	Limit  int
+	Offset int
+}
+
+type ArtistPage struct {
+	Items  []Artist `json:"items"`
+	Total  int      `json:"total"`
+	Limit  int      `json:"limit"`
+	Offset int      `json:"offset"`
+	Next   string   `json:"next,omitempty"`
+}
+
+type Album struct {
+	ID                   int    `json:"id"`
+	Title                string `json:"title"`
+	ArtistID             int    `json:"artistId"`
+	Artist               string `json:"artist" readonly:"true"`
+	ReleaseDate          string `json:"releaseDate" example:"2006-07-03"`
+	ReleaseDatePrecision string `json:"releaseDatePrecision,omitempty" enums:"day,month,year"`
+	// Tracks are ordered by disc and track number, they are only filled
+	// in when a single album is requested.
+	Tracks []Song `json:"tracks,omitempty" readonly:"true"`
+}
+
+// Track places a song on an album.
+type Track struct {
+	SongID      int `json:"songId"`
+	DiscNumber  int `json:"discNumber"`
+	TrackNumber int `json:"trackNumber"`
+}
+
+type AlbumQuery struct {
+	ArtistID int
+	// Title matches albums whose title contains it, case-insensitively.
+	Title  string
+	Limit  int
+	Offset int
+}
+
+type AlbumPage struct {
+	Items  []Album `json:"items"`
+	Total  int     `json:"total"`
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
+	Next   string  `json:"next,omitempty"`
+}
+
+type Tag struct {
+	Name string `json:"name" example:"rock"`
+	Kind string `json:"kind,omitempty" example:"genre"`
+}
+
+type TagCount struct {
+	Name  string `json:"name"`
+	Kind  string `json:"kind,omitempty"`
+	Count int    `json:"count"`
+}
+
+// Facets counts the tags of the songs matching a query.
+type Facets struct {
+	Total int        `json:"total"`
+	Tags  []TagCount `json:"tags"`
+}
+
+type Playlist struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// Entries are ordered by position, they are only filled in when a
+	// single playlist is requested.
+	Entries []PlaylistEntry `json:"entries,omitempty" readonly:"true"`
+}
+
+// PlaylistEntry is a song at a position of a playlist, the same song may
+// be in a playlist more than once.
+type PlaylistEntry struct {
+	ID       int  `json:"id"`
+	Position int  `json:"position"`
+	Song     Song `json:"song"`
+}
+
+// PlaylistInsert puts a song into a playlist at the position, entries
+// from that position on move down. Without a position the song is
+// appended.
+type PlaylistInsert struct {
+	SongID   int `json:"songId"`
+	Position int `json:"position,omitempty"`
+}
+
+// PlaylistMove moves an entry to the position.
+type PlaylistMove struct {
+	Position int `json:"position"`
+}
+
+type PlaylistQuery struct {
+	// Name matches playlists whose name contains it, case-insensitively.
+	Name   string
+	Limit  int
+	Offset int
+}
+
+type PlaylistPage struct {
+	Items  []Playlist `json:"items"`
+	Total  int        `json:"total"`
+	Limit  int        `json:"limit"`
+	Offset int        `json:"offset"`
+	Next   string     `json:"next,omitempty"`
+}
+
+type SearchQuery struct {
+	// Query is a web search style query: words, "quoted phrases", or
+	// and -excluded words.
+	Query  string
+	Limit  int
+	Offset int
+}
+
+// SearchResult is a song found by a full-text search, best matches have
+// the highest rank.
+type SearchResult struct {
+	Song
+	Rank float64 `json:"rank"`
+	// Snippet is a fragment of the lyrics with matches wrapped in <b>.
+	Snippet string `json:"snippet,omitempty" example:"I'm <b>waiting</b> in the dark"`
+}
+
+type SearchPage struct {
+	Items  []SearchResult `json:"items"`
+	Total  int            `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+	Next   string         `json:"next,omitempty"`
+	// Suggestions are close band names and titles, "did you mean", when
+	// nothing is found.
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+type FuzzyQuery struct {
+	// Query is compared to the band, the title and both of them.
+	Query string
+	// MinScore is the similarity a match must exceed, from 0 to 1.
+	MinScore float64
+	Limit    int
+}
+
+// FuzzyMatch is a song similar to a fuzzy query.
+type FuzzyMatch struct {
+	Song
+	// Score is the trigram similarity to the query, 1 is an exact match.
+	Score float64 `json:"score" example:"0.55"`
+	// Match is the band, title or "band title" closest to the query.
+	Match string `json:"match" example:"Supermassive Black Hole"`
+}
+
+type FuzzyPage struct {
+	Items []FuzzyMatch `json:"items"`
+	Limit int          `json:"limit"`
+}
+
+type DuplicateQuery struct {
+	// MinScore is the lowest similarity of the lyrics of a duplicate to
+	// the first song of its group, from 0 to 1.
+	MinScore float64
+	Limit    int
+	Offset   int
+}
+
+// DuplicateGroup is a set of songs with the same band and title up to
+// case, punctuation and script. The oldest song comes first, it is the
+// one to merge the others into.
+type DuplicateGroup struct {
+	Group string      `json:"group"`
+	Song  string      `json:"song"`
+	Songs []Duplicate `json:"songs"`
+}
+
+type Duplicate struct {
+	Song
+	// Score is the similarity of the lyrics to the first song of the
+	// group, songs without lyrics score 1.
+	Score float64 `json:"score" example:"0.93"`
+}
+
+type DuplicatePage struct {
+	Items  []DuplicateGroup `json:"items"`
+	Total  int              `json:"total"`
+	Limit  int              `json:"limit"`
+	Offset int              `json:"offset"`
+	Next   string           `json:"next,omitempty"`
+}
+
+// SongMerge lists the duplicates folded into a song.
+type SongMerge struct {
+	Duplicates []int `json:"duplicates" example:"7"`
+	// Force merges songs with another band or title.
+	Force bool `json:"force,omitempty"`
+}
+
+// IdempotencyRecord is a request made with an Idempotency-Key and the
+// response to it. Status is 0 until the response is saved, until then
+// ExpiresAt is the end of a short lease on the key.
+type IdempotencyRecord struct {
+	Key         string
+	RequestHash string
+	Status      int
+	ContentType string
+	Body        string
+	ExpiresAt   time.Time
 }
 
 type SongDetail struct {
@@ -14,3 +264,15 @@ type SongDetail struct {
 	Text        string `json:"text"`
 	Link        string `json:"link"`
 }
+
+type SongText struct {
+	ID      int      `json:"id"`
+	Group   string   `json:"group"`
+	Song    string   `json:"song"`
+	Page    int      `json:"page"`
+	PerPage int      `json:"perPage"`
+	Total   int      `json:"total"`
+	Verses  []string `json:"verses"`
+	Next    string   `json:"next,omitempty"`
+	Prev    string   `json:"prev,omitempty"`
+}
@@ -0,0 +1,323 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+)
+
+// ErrInvalidPosition is returned when a playlist position is out of
+// range.
+var ErrInvalidPosition = errors.New("invalid position")
+
+// entryPosition is a playlist entry as stored, positions may have gaps
+// left by deleted songs until the playlist is renumbered.
+type entryPosition struct {
+	id       int
+	songID   int
+	position int
+}
+
+func (r *Storage) CreatePlaylist(ctx context.Context, playlist *Playlist) error {
+	query := `INSERT INTO playlists (name) VALUES ($1) RETURNING id`
+	if err := r.conn().QueryRow(ctx, query, playlist.Name).Scan(&playlist.ID); err != nil {
+		log.Printf("Error creating playlist: %v", err)
+		return mapError(err)
+	}
+	return nil
+}
+
+// GetPlaylist returns the playlist with its entries and their songs.
+// Entries are read joined with their songs, so that a song deleted in the
+// meantime drops out instead of leaving an entry without a song.
+func (r *Storage) GetPlaylist(ctx context.Context, id int) (*Playlist, error) {
+	c := r.conn()
+	playlist, err := getPlaylist(ctx, c, id, "")
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT s.*, e.id FROM playlist_entries e
+		JOIN (SELECT ` + songColumns + ` FROM songs) s ON s.id = e.song_id
+		WHERE e.playlist_id = $1
+		ORDER BY e.position, e.id`
+	rows, err := c.Query(ctx, query, id)
+	if err != nil {
+		log.Printf("Error getting playlist entries: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	playlist.Entries = []PlaylistEntry{}
+	for rows.Next() {
+		var entry PlaylistEntry
+		if err := scanSong(entryScanner{rows, &entry}, &entry.Song); err != nil {
+			log.Printf("Error scanning playlist entry: %v", err)
+			return nil, err
+		}
+		entry.Position = len(playlist.Entries) + 1
+		playlist.Entries = append(playlist.Entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error getting playlist entries: %v", err)
+		return nil, err
+	}
+
+	songs := make([]*Song, len(playlist.Entries))
+	for i := range playlist.Entries {
+		songs[i] = &playlist.Entries[i].Song
+	}
+	if err := loadTags(ctx, c, songs...); err != nil {
+		return nil, err
+	}
+	return playlist, nil
+}
+
+// entryScanner appends the entry id to the song columns scanned by
+// scanSong.
+type entryScanner struct {
+	row   scanner
+	entry *PlaylistEntry
+}
+
+func (s entryScanner) Scan(dest ...any) error {
+	return s.row.Scan(append(dest, &s.entry.ID)...)
+}
+
+func getPlaylist(ctx context.Context, c conn, id int, lock string) (*Playlist, error) {
+	var playlist Playlist
+	err := c.QueryRow(ctx, `SELECT id, name FROM playlists WHERE id = $1`+lock, id).Scan(&playlist.ID, &playlist.Name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		log.Printf("Error getting playlist: %v", err)
+		return nil, err
+	}
+	return &playlist, nil
+}
+
+func playlistEntries(ctx context.Context, c conn, playlistID int) ([]entryPosition, error) {
+	query := `SELECT id, song_id, position FROM playlist_entries WHERE playlist_id = $1 ORDER BY position, id`
+	rows, err := c.Query(ctx, query, playlistID)
+	if err != nil {
+		log.Printf("Error getting playlist entries: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []entryPosition
+	for rows.Next() {
+		var e entryPosition
+		if err := rows.Scan(&e.id, &e.songID, &e.position); err != nil {
+			log.Printf("Error scanning playlist entry: %v", err)
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error getting playlist entries: %v", err)
+		return nil, err
+	}
+	return entries, nil
+}
+
+// renumber stores the order of the entries as positions 1 to n, only
+// entries whose position changed are written.
+func renumber(ctx context.Context, c conn, entries []entryPosition) error {
+	for i, e := range entries {
+		if e.position == i+1 {
+			continue
+		}
+		if _, err := c.Exec(ctx, `UPDATE playlist_entries SET position = $1 WHERE id = $2`, i+1, e.id); err != nil {
+			log.Printf("Error renumbering playlist entries: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Storage) UpdatePlaylist(ctx context.Context, playlist *Playlist) error {
+	res, err := r.conn().Exec(ctx, `UPDATE playlists SET name = $1 WHERE id = $2`, playlist.Name, playlist.ID)
+	if err != nil {
+		log.Printf("Error updating playlist: %v", err)
+		return mapError(err)
+	}
+	return checkAffected(res)
+}
+
+// DeletePlaylist deletes the playlist with its entries, the songs stay
+// in the library.
+func (r *Storage) DeletePlaylist(ctx context.Context, id int) error {
+	res, err := r.conn().Exec(ctx, `DELETE FROM playlists WHERE id = $1`, id)
+	if err != nil {
+		log.Printf("Error deleting playlist: %v", err)
+		return err
+	}
+	return checkAffected(res)
+}
+
+func (r *Storage) GetPlaylists(ctx context.Context, q PlaylistQuery) (*PlaylistPage, error) {
+	where, args := " WHERE 1=1", []any{}
+	if q.Name != "" {
+		args = append(args, "%"+escapeLike(q.Name)+"%")
+		where += " AND name " + r.dialect.like + " $1 ESCAPE '\\'"
+	}
+
+	page := &PlaylistPage{Items: []Playlist{}, Limit: q.Limit, Offset: q.Offset}
+	if err := r.conn().QueryRow(ctx, `SELECT count(*) FROM playlists`+where, args...).Scan(&page.Total); err != nil {
+		log.Printf("Error counting playlists: %v", err)
+		return nil, err
+	}
+
+	args = append(args, q.Limit, q.Offset)
+	query := `SELECT id, name FROM playlists` + where +
+		` ORDER BY id LIMIT $` + strconv.Itoa(len(args)-1) + ` OFFSET $` + strconv.Itoa(len(args))
+
+	rows, err := r.conn().Query(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error getting playlists: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var playlist Playlist
+		if err := rows.Scan(&playlist.ID, &playlist.Name); err != nil {
+			log.Printf("Error scanning playlist: %v", err)
+			return nil, err
+		}
+		page.Items = append(page.Items, playlist)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error getting playlists: %v", err)
+		return nil, err
+	}
+	return page, nil
+}
+
+// editPlaylist runs edit in a transaction holding the playlist row lock,
+// so concurrent edits of a playlist apply one after another. Edit gets
+// the entries in order and returns them in their new order.
+func (r *Storage) editPlaylist(ctx context.Context, playlistID int,
+	edit func(c conn, entries []entryPosition) ([]entryPosition, error)) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	c := conn{q: tx, dialect: &r.dialect}
+	if _, err := getPlaylist(ctx, c, playlistID, r.dialect.lock); err != nil {
+		return err
+	}
+	entries, err := playlistEntries(ctx, c, playlistID)
+	if err != nil {
+		return err
+	}
+	if entries, err = edit(c, entries); err != nil {
+		return err
+	}
+	if err := renumber(ctx, c, entries); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return err
+	}
+	return nil
+}
+
+// InsertEntry puts the song into the playlist at the position, 0 appends
+// it.
+func (r *Storage) InsertEntry(ctx context.Context, playlistID int, insert PlaylistInsert) (*Playlist, error) {
+	err := r.editPlaylist(ctx, playlistID, func(c conn, entries []entryPosition) ([]entryPosition, error) {
+		at, err := insertIndex(insert.Position, len(entries))
+		if err != nil {
+			return nil, err
+		}
+
+		var id int
+		err = c.QueryRow(ctx, `SELECT id FROM songs WHERE id = $1`, insert.SongID).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %d", ErrUnknownSong, insert.SongID)
+		} else if err != nil {
+			log.Printf("Error getting song: %v", err)
+			return nil, err
+		}
+
+		// Position 0 never matches, renumber sets the real one.
+		query := `INSERT INTO playlist_entries (playlist_id, song_id, position) VALUES ($1, $2, 0) RETURNING id`
+		e := entryPosition{songID: insert.SongID}
+		if err := c.QueryRow(ctx, query, playlistID, insert.SongID).Scan(&e.id); err != nil {
+			log.Printf("Error creating playlist entry: %v", err)
+			return nil, mapError(err)
+		}
+		return slices.Insert(entries, at, e), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetPlaylist(ctx, playlistID)
+}
+
+// MoveEntry moves the entry to the position, the entries in between
+// shift by one.
+func (r *Storage) MoveEntry(ctx context.Context, playlistID, entryID int, move PlaylistMove) (*Playlist, error) {
+	err := r.editPlaylist(ctx, playlistID, func(c conn, entries []entryPosition) ([]entryPosition, error) {
+		return moveEntry(entries, entryID, move.Position)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetPlaylist(ctx, playlistID)
+}
+
+// RemoveEntry takes the entry out of the playlist, the entries after it
+// move up.
+func (r *Storage) RemoveEntry(ctx context.Context, playlistID, entryID int) error {
+	return r.editPlaylist(ctx, playlistID, func(c conn, entries []entryPosition) ([]entryPosition, error) {
+		i := slices.IndexFunc(entries, func(e entryPosition) bool { return e.id == entryID })
+		if i < 0 {
+			return nil, ErrNotFound
+		}
+		if _, err := c.Exec(ctx, `DELETE FROM playlist_entries WHERE id = $1`, entryID); err != nil {
+			log.Printf("Error deleting playlist entry: %v", err)
+			return nil, err
+		}
+		return slices.Delete(entries, i, i+1), nil
+	})
+}
+
+// insertIndex converts a 1-based insert position into a slice index, 0
+// appends.
+func insertIndex(position, n int) (int, error) {
+	if position == 0 {
+		return n, nil
+	}
+	if position < 1 || position > n+1 {
+		return 0, fmt.Errorf("%w: must be between 1 and %d", ErrInvalidPosition, n+1)
+	}
+	return position - 1, nil
+}
+
+// moveEntry moves the entry with the id to the 1-based position.
+func moveEntry(entries []entryPosition, id, position int) ([]entryPosition, error) {
+	i := slices.IndexFunc(entries, func(e entryPosition) bool { return e.id == id })
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+	if position < 1 || position > len(entries) {
+		return nil, fmt.Errorf("%w: must be between 1 and %d", ErrInvalidPosition, len(entries))
+	}
+
+	e := entries[i]
+	entries = slices.Delete(entries, i, i+1)
+	return slices.Insert(entries, position-1, e), nil
+}
@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// entrySongs returns the song ids of the playlist entries, checking that
+// positions are numbered from 1 without gaps.
+func entrySongs(t *testing.T, playlist *Playlist) []int {
+	t.Helper()
+	ids := []int{}
+	for i, e := range playlist.Entries {
+		if e.Position != i+1 {
+			t.Errorf("entry %d of song %d at position %d, want %d", e.ID, e.Song.ID, e.Position, i+1)
+		}
+		ids = append(ids, e.Song.ID)
+	}
+	return ids
+}
+
+func TestPlaylists(t *testing.T) {
+	forEachRepository(t, testPlaylists)
+}
+
+func testPlaylists(t *testing.T, newRepo func(...*Song) Repository) {
+	ctx := context.Background()
+	repo := newRepo(
+		&Song{Group: "Muse", Song: "Hole"},
+		&Song{Group: "Muse", Song: "Uprising"},
+		&Song{Group: "Kino", Song: "Kukushka"},
+	)
+
+	playlist := &Playlist{Name: "Road trip"}
+	if err := repo.CreatePlaylist(ctx, playlist); err != nil {
+		t.Fatal(err)
+	}
+
+	insert := func(songID, position int) *Playlist {
+		t.Helper()
+		got, err := repo.InsertEntry(ctx, playlist.ID, PlaylistInsert{SongID: songID, Position: position})
+		if err != nil {
+			t.Fatalf("InsertEntry(%d, %d): %v", songID, position, err)
+		}
+		return got
+	}
+	insert(1, 0)
+	insert(2, 0)
+	insert(3, 1)
+	got := insert(1, 3)
+	// The same song may be in a playlist twice.
+	if ids := entrySongs(t, got); !reflect.DeepEqual(ids, []int{3, 1, 1, 2}) {
+		t.Fatalf("entries = %v, want [3 1 1 2]", ids)
+	}
+	if got.Entries[0].Song.Song != "Kukushka" {
+		t.Errorf("entry song = %+v, want Kukushka", got.Entries[0].Song)
+	}
+
+	if _, err := repo.InsertEntry(ctx, playlist.ID, PlaylistInsert{SongID: 1, Position: 6}); !errors.Is(err, ErrInvalidPosition) {
+		t.Errorf("InsertEntry past the end err = %v, want ErrInvalidPosition", err)
+	}
+	if _, err := repo.InsertEntry(ctx, playlist.ID, PlaylistInsert{SongID: 9}); !errors.Is(err, ErrUnknownSong) {
+		t.Errorf("InsertEntry of a missing song err = %v, want ErrUnknownSong", err)
+	}
+	if _, err := repo.InsertEntry(ctx, 9, PlaylistInsert{SongID: 1}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("InsertEntry into a missing playlist err = %v, want ErrNotFound", err)
+	}
+
+	last := got.Entries[3].ID
+	if got, err := repo.MoveEntry(ctx, playlist.ID, last, PlaylistMove{Position: 1}); err != nil {
+		t.Fatal(err)
+	} else if ids := entrySongs(t, got); !reflect.DeepEqual(ids, []int{2, 3, 1, 1}) {
+		t.Errorf("entries after MoveEntry = %v, want [2 3 1 1]", ids)
+	}
+	if _, err := repo.MoveEntry(ctx, playlist.ID, last, PlaylistMove{Position: 5}); !errors.Is(err, ErrInvalidPosition) {
+		t.Errorf("MoveEntry past the end err = %v, want ErrInvalidPosition", err)
+	}
+	if _, err := repo.MoveEntry(ctx, playlist.ID, 99, PlaylistMove{Position: 1}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("MoveEntry of a missing entry err = %v, want ErrNotFound", err)
+	}
+
+	if err := repo.RemoveEntry(ctx, playlist.ID, last); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.RemoveEntry(ctx, playlist.ID, last); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second RemoveEntry err = %v, want ErrNotFound", err)
+	}
+	// Deleting a song removes its entries and closes the gaps.
+	if err := repo.Delete(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.AddTags(ctx, 1, []Tag{{Name: "rock"}}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetPlaylist(ctx, playlist.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := entrySongs(t, got); !reflect.DeepEqual(ids, []int{1, 1}) {
+		t.Errorf("entries after deleting a song = %v, want [1 1]", ids)
+	}
+	// Every entry of a song has its tags.
+	for _, e := range got.Entries {
+		if !reflect.DeepEqual(e.Song.Tags, []string{"rock"}) {
+			t.Errorf("entry %d tags = %v, want [rock]", e.ID, e.Song.Tags)
+		}
+	}
+
+	playlist.Name = "Commute"
+	if err := repo.UpdatePlaylist(ctx, playlist); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.UpdatePlaylist(ctx, &Playlist{ID: 9, Name: "Commute"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("UpdatePlaylist of a missing playlist err = %v, want ErrNotFound", err)
+	}
+	if err := repo.DeletePlaylist(ctx, playlist.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetPlaylist(ctx, playlist.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetPlaylist after DeletePlaylist err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestGetPlaylists(t *testing.T) {
+	forEachRepository(t, testGetPlaylists)
+}
+
+func testGetPlaylists(t *testing.T, newRepo func(...*Song) Repository) {
+	ctx := context.Background()
+	repo := newRepo()
+	for _, name := range []string{"Road trip", "Workout", "Night drive"} {
+		if err := repo.CreatePlaylist(ctx, &Playlist{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		q    PlaylistQuery
+		want []string
+	}{
+		{PlaylistQuery{}, []string{"Road trip", "Workout", "Night drive"}},
+		{PlaylistQuery{Name: "R"}, []string{"Road trip", "Workout", "Night drive"}},
+		{PlaylistQuery{Name: "rIvE"}, []string{"Night drive"}},
+		{PlaylistQuery{Offset: 1, Limit: 1}, []string{"Workout"}},
+	}
+	for _, tt := range tests {
+		if tt.q.Limit == 0 {
+			tt.q.Limit = 10
+		}
+		page, err := repo.GetPlaylists(ctx, tt.q)
+		if err != nil {
+			t.Errorf("GetPlaylists(%+v): %v", tt.q, err)
+			continue
+		}
+		var got []string
+		for _, playlist := range page.Items {
+			got = append(got, playlist.Name)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetPlaylists(%+v) = %q of %d, want %q", tt.q, got, page.Total, tt.want)
+		}
+	}
+}
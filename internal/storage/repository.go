@@ -0,0 +1,86 @@
+package storage
+
+import "context"
+
+// SongRepository is the storage used by the application. Storage keeps
+// songs in a SQL database, MemoryStorage keeps them in memory.
+type SongRepository interface {
+	Create(ctx context.Context, song *Song) error
+	GetByID(ctx context.Context, id int) (*Song, error)
+	GetByName(ctx context.Context, group, title string) (*Song, error)
+	Update(ctx context.Context, song *Song) error
+	UpdateFunc(ctx context.Context, id int, fn func(song *Song) error) (*Song, error)
+	Delete(ctx context.Context, id int) error
+	GetList(ctx context.Context, q ListQuery) (*SongPage, error)
+}
+
+type ArtistRepository interface {
+	CreateArtist(ctx context.Context, artist *Artist) error
+	GetArtist(ctx context.Context, id int) (*Artist, error)
+	UpdateArtist(ctx context.Context, artist *Artist) error
+	DeleteArtist(ctx context.Context, id int) error
+	GetArtists(ctx context.Context, q ArtistQuery) (*ArtistPage, error)
+}
+
+type AlbumRepository interface {
+	CreateAlbum(ctx context.Context, album *Album) error
+	GetAlbum(ctx context.Context, id int) (*Album, error)
+	UpdateAlbum(ctx context.Context, album *Album) error
+	DeleteAlbum(ctx context.Context, id int) error
+	GetAlbums(ctx context.Context, q AlbumQuery) (*AlbumPage, error)
+	SetTracks(ctx context.Context, albumID int, tracks []Track) (*Album, error)
+	RemoveTrack(ctx context.Context, albumID, songID int) error
+}
+
+type TagRepository interface {
+	AddTags(ctx context.Context, songID int, tags []Tag) error
+	RemoveTags(ctx context.Context, songID int, names []string) error
+	Facets(ctx context.Context, q ListQuery, kind string) (*Facets, error)
+}
+
+type PlaylistRepository interface {
+	CreatePlaylist(ctx context.Context, playlist *Playlist) error
+	GetPlaylist(ctx context.Context, id int) (*Playlist, error)
+	UpdatePlaylist(ctx context.Context, playlist *Playlist) error
+	DeletePlaylist(ctx context.Context, id int) error
+	GetPlaylists(ctx context.Context, q PlaylistQuery) (*PlaylistPage, error)
+	InsertEntry(ctx context.Context, playlistID int, insert PlaylistInsert) (*Playlist, error)
+	MoveEntry(ctx context.Context, playlistID, entryID int, move PlaylistMove) (*Playlist, error)
+	RemoveEntry(ctx context.Context, playlistID, entryID int) error
+}
+
+type SearchRepository interface {
+	Search(ctx context.Context, q SearchQuery) (*SearchPage, error)
+	Fuzzy(ctx context.Context, q FuzzyQuery) (*FuzzyPage, error)
+}
+
+type DuplicateRepository interface {
+	Duplicates(ctx context.Context, q DuplicateQuery) (*DuplicatePage, error)
+	Merge(ctx context.Context, id int, duplicates []int, force bool) (*Song, error)
+	Redirect(ctx context.Context, id int) (int, error)
+}
+
+// IdempotencyRepository keeps the responses to requests sent with an
+// Idempotency-Key, see Storage.ReserveKey.
+type IdempotencyRepository interface {
+	ReserveKey(ctx context.Context, rec IdempotencyRecord) (*IdempotencyRecord, error)
+	SaveResponse(ctx context.Context, rec IdempotencyRecord) error
+	ReleaseKey(ctx context.Context, key string) error
+}
+
+// Repository combines the repositories of all entities.
+type Repository interface {
+	SongRepository
+	ArtistRepository
+	AlbumRepository
+	TagRepository
+	PlaylistRepository
+	SearchRepository
+	DuplicateRepository
+	IdempotencyRepository
+}
+
+var (
+	_ Repository = (*Storage)(nil)
+	_ Repository = (*MemoryStorage)(nil)
+)
@@ -0,0 +1,315 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// repositories creates empty repositories of every kind.
+var repositories = []struct {
+	name string
+	new  func(t *testing.T) Repository
+}{
+	{"memory", func(*testing.T) Repository { return NewMemoryStorage() }},
+	{"sqlite", func(t *testing.T) Repository { return newTestSQLite(t) }},
+}
+
+// forEachRepository runs test against every kind of repository. newRepo
+// returns a repository holding the songs, their ids are filled in.
+func forEachRepository(t *testing.T, test func(t *testing.T, newRepo func(songs ...*Song) Repository)) {
+	for _, r := range repositories {
+		t.Run(r.name, func(t *testing.T) {
+			test(t, func(songs ...*Song) Repository {
+				t.Helper()
+				repo := r.new(t)
+				for _, song := range songs {
+					if err := repo.Create(context.Background(), song); err != nil {
+						t.Fatalf("Create(%s - %s): %v", song.Group, song.Song, err)
+					}
+				}
+				return repo
+			})
+		})
+	}
+}
+
+func songIDs(songs []Song) []int {
+	ids := []int{}
+	for _, song := range songs {
+		ids = append(ids, song.ID)
+	}
+	return ids
+}
+
+func TestSongs(t *testing.T) {
+	forEachRepository(t, testSongs)
+}
+
+func testSongs(t *testing.T, newRepo func(...*Song) Repository) {
+	ctx := context.Background()
+	song := &Song{Group: "Muse", Song: "Hole", ReleaseDate: "2006-01-01", ReleaseDatePrecision: "year"}
+	repo := newRepo(song)
+
+	got, err := repo.GetByID(ctx, song.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Song != "Hole" || got.ReleaseDate != "2006-01-01" || got.ReleaseDatePrecision != "year" {
+		t.Errorf("GetByID = %+v, want the song", got)
+	}
+
+	got.Text = "lyrics"
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := repo.UpdateFunc(ctx, song.ID, func(s *Song) error {
+		s.Link = "https://example.com"
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Text != "lyrics" || updated.Link != "https://example.com" {
+		t.Errorf("UpdateFunc = %+v, want text and link set", updated)
+	}
+
+	failed := errors.New("failed")
+	if _, err := repo.UpdateFunc(ctx, song.ID, func(s *Song) error {
+		s.Text = "lost"
+		return failed
+	}); !errors.Is(err, failed) {
+		t.Errorf("UpdateFunc err = %v, want %v", err, failed)
+	}
+	if got, _ := repo.GetByID(ctx, song.ID); got.Text != "lyrics" {
+		t.Errorf("failed UpdateFunc changed the text to %q", got.Text)
+	}
+
+	if err := repo.Update(ctx, &Song{ID: 9, Group: "Muse", Song: "Hole"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update of a missing song err = %v, want ErrNotFound", err)
+	}
+	if err := repo.Delete(ctx, song.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetByID(ctx, song.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetByID after Delete err = %v, want ErrNotFound", err)
+	}
+	if err := repo.Delete(ctx, song.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete err = %v, want ErrNotFound", err)
+	}
+}
+
+// uniqueSongs is implemented by the repositories that can keep songs
+// unique, see Storage.SetUniqueSongs.
+type uniqueSongs interface {
+	SetUniqueSongs(ctx context.Context, unique bool) error
+}
+
+func TestUniqueSongs(t *testing.T) {
+	forEachRepository(t, testUniqueSongs)
+}
+
+func testUniqueSongs(t *testing.T, newRepo func(...*Song) Repository) {
+	ctx := context.Background()
+	repo := newRepo(
+		&Song{Group: "Кино", Song: "Группа крови"},
+		&Song{Group: "Muse", Song: "Hole"},
+		&Song{Group: "KINO", Song: "Gruppa krovi"},
+	)
+	unique := repo.(uniqueSongs)
+
+	// The oldest of the songs with the name is found.
+	got, err := repo.GetByName(ctx, "kino", "gruppa krovi!")
+	if err != nil || got.ID != 1 {
+		t.Errorf("GetByName = %+v, %v, want song 1", got, err)
+	}
+	if _, err := repo.GetByName(ctx, "Muse", "Uprising"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetByName of a missing song err = %v, want ErrNotFound", err)
+	}
+
+	if err := unique.SetUniqueSongs(ctx, true); !errors.Is(err, ErrDuplicateSongs) {
+		t.Errorf("SetUniqueSongs with duplicates err = %v, want ErrDuplicateSongs", err)
+	}
+	if err := repo.Delete(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := unique.SetUniqueSongs(ctx, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.Create(ctx, &Song{Group: "Kino!", Song: "Gruppa krovi"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("Create of a duplicate err = %v, want ErrConflict", err)
+	}
+	// The artist Kino! created for the duplicate is rolled back.
+	if page, err := repo.GetArtists(ctx, ArtistQuery{Limit: 10}); err != nil || page.Total != 3 {
+		t.Errorf("GetArtists = %+v, %v, want 3 artists", page, err)
+	}
+	if err := repo.Update(ctx, &Song{ID: 2, Group: "kino", Song: "Группа Крови"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("Update into a duplicate err = %v, want ErrConflict", err)
+	}
+	// A song keeps its own name.
+	if err := repo.Update(ctx, &Song{ID: 1, Group: "Кино", Song: "Группа крови!"}); err != nil {
+		t.Errorf("Update of the title punctuation: %v", err)
+	}
+
+	// Names without letters or digits have no key and are never the same,
+	// letters of other scripts are kept.
+	for _, song := range []*Song{
+		{Group: "!!!", Song: "?"},
+		{Group: "!!!", Song: "?"},
+		{Group: "Beyoncé", Song: "Halo"},
+		{Group: "Beyonc", Song: "Halo"},
+	} {
+		if err := repo.Create(ctx, song); err != nil {
+			t.Errorf("Create(%s - %s): %v", song.Group, song.Song, err)
+		}
+	}
+	if _, err := repo.GetByName(ctx, "!!!", "?"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetByName of a name without a key err = %v, want ErrNotFound", err)
+	}
+
+	if err := unique.SetUniqueSongs(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Create(ctx, &Song{Group: "KINO", Song: "Gruppa krovi"}); err != nil {
+		t.Errorf("Create of a duplicate without unique songs: %v", err)
+	}
+}
+
+func TestGetList(t *testing.T) {
+	forEachRepository(t, testGetList)
+}
+
+func testGetList(t *testing.T, newRepo func(...*Song) Repository) {
+	ctx := context.Background()
+	songs := []*Song{
+		{Group: "Muse", Song: "Hole"},
+		{Group: "Muse", Song: "Uprising"},
+		{Group: "Kino", Song: "Gruppa krovi"},
+		{Group: "Kino", Song: "Kukushka"},
+	}
+	repo := newRepo(songs...)
+
+	cond := func(field string, op Operator, value string) Condition {
+		return Condition{Field: field, Op: op, Value: value}
+	}
+	tests := []struct {
+		name string
+		q    ListQuery
+		want []int
+	}{
+		{"all", ListQuery{}, []int{1, 2, 3, 4}},
+		{"eq", ListQuery{Filter: Filter{cond("group", OpEq, "Kino")}}, []int{3, 4}},
+		{"ne", ListQuery{Filter: Filter{cond("group", OpNe, "Kino")}}, []int{1, 2}},
+		{"contains", ListQuery{Filter: Filter{cond("song", OpContains, "RIS")}}, []int{2}},
+		{"prefix", ListQuery{Filter: Filter{cond("song", OpPrefix, "ku")}}, []int{4}},
+		{"range", ListQuery{Filter: Filter{cond("song", OpGt, "H"), cond("song", OpLt, "L")}}, []int{1, 4}},
+		{"sort", ListQuery{Sort: Sort{{Field: "song", Desc: true}}}, []int{2, 4, 1, 3}},
+		{"sort by two keys", ListQuery{Sort: Sort{{Field: "group"}, {Field: "song", Desc: true}}}, []int{4, 3, 2, 1}},
+		{"offset", ListQuery{Offset: 3}, []int{4}},
+		{"offset past the end", ListQuery{Offset: 9}, []int{}},
+	}
+	for _, tt := range tests {
+		if tt.q.Limit == 0 {
+			tt.q.Limit = 10
+		}
+		page, err := repo.GetList(ctx, tt.q)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := songIDs(page.Items); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ids = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReleaseDates(t *testing.T) {
+	forEachRepository(t, testReleaseDates)
+}
+
+func testReleaseDates(t *testing.T, newRepo func(...*Song) Repository) {
+	ctx := context.Background()
+	repo := newRepo(
+		&Song{Group: "Muse", Song: "Hole", ReleaseDate: "2006-07-16"},
+		&Song{Group: "Muse", Song: "Uprising"},
+		&Song{Group: "Muse", Song: "Starlight", ReleaseDate: "2006-09-01", ReleaseDatePrecision: "month"},
+		&Song{Group: "Muse", Song: "Dead Star", ReleaseDate: "2002-06-09"},
+	)
+
+	cond := func(op Operator, value string) Condition {
+		return Condition{Field: "releaseDate", Op: op, Value: value}
+	}
+	tests := []struct {
+		name string
+		q    ListQuery
+		want []int
+	}{
+		{"eq", ListQuery{Filter: Filter{cond(OpEq, "2006-07-16")}}, []int{1}},
+		{"ne skips songs without a date", ListQuery{Filter: Filter{cond(OpNe, "2006-07-16")}}, []int{3, 4}},
+		{"range", ListQuery{Filter: Filter{cond(OpGte, "2006-01-01"), cond(OpLt, "2007-01-01")}}, []int{1, 3}},
+		{"any of", ListQuery{Filter: Filter{AnyOf(cond(OpLt, "2006-01-01"), cond(OpGte, "2006-08-01"))}}, []int{3, 4}},
+		{"sort puts songs without a date first", ListQuery{Sort: Sort{{Field: "releaseDate"}}}, []int{2, 4, 1, 3}},
+		{"sort desc", ListQuery{Sort: Sort{{Field: "releaseDate", Desc: true}}}, []int{3, 1, 4, 2}},
+	}
+	for _, tt := range tests {
+		tt.q.Limit = 10
+		page, err := repo.GetList(ctx, tt.q)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := songIDs(page.Items); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ids = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Songs without a date stay in place when paging by cursor.
+	q := ListQuery{Sort: Sort{{Field: "releaseDate", Desc: true}}, Limit: 3}
+	page, err := repo.GetList(ctx, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.Cursor = page.NextCursor
+	if page, err = repo.GetList(ctx, q); err != nil || !reflect.DeepEqual(songIDs(page.Items), []int{2}) {
+		t.Errorf("second page = %v, %v, want [2]", page, err)
+	}
+}
+
+func TestCursorPagination(t *testing.T) {
+	forEachRepository(t, testCursorPagination)
+}
+
+func testCursorPagination(t *testing.T, newRepo func(...*Song) Repository) {
+	ctx := context.Background()
+	repo := newRepo(
+		&Song{Group: "A", Song: "1", Link: "b"},
+		&Song{Group: "A", Song: "2"},
+		&Song{Group: "A", Song: "3", Link: "b"},
+		&Song{Group: "A", Song: "4", Link: "c"},
+		&Song{Group: "A", Song: "5"},
+	)
+
+	q := ListQuery{Sort: Sort{{Field: "link", Desc: true}}, Limit: 2}
+	var got []int
+	for range 5 {
+		page, err := repo.GetList(ctx, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, songIDs(page.Items)...)
+		if page.NextCursor == "" {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+	if want := []int{4, 1, 3, 2, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pages = %v, want %v", got, want)
+	}
+
+	q.Cursor = encodeCursor(Sort{{Field: "group"}}, Song{ID: 1})
+	if _, err := repo.GetList(ctx, q); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("cursor of another sort err = %v, want ErrInvalidCursor", err)
+	}
+}
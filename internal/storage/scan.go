@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const songColumns = `id, band, COALESCE(artist_id, 0), song, release_date, COALESCE(release_date_precision, ''), text, link,
+	COALESCE(album_id, 0), COALESCE(disc_number, 0), COALESCE(track_number, 0)`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSong(row scanner, song *Song) error {
+	return row.Scan(&song.ID, &song.Group, &song.ArtistID, &song.Song,
+		dateColumn{&song.ReleaseDate}, &song.ReleaseDatePrecision, &song.Text, &song.Link,
+		&song.AlbumID, &song.DiscNumber, &song.TrackNumber)
+}
+
+// dateColumn reads a nullable DATE column as an ISO date string: lib/pq
+// returns time.Time, SQLite returns the stored text.
+type dateColumn struct {
+	value *string
+}
+
+func (d dateColumn) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*d.value = ""
+	case time.Time:
+		*d.value = v.Format("2006-01-02")
+	case string:
+		*d.value = v[:min(len(v), 10)]
+	case []byte:
+		*d.value = string(v[:min(len(v), 10)])
+	default:
+		return fmt.Errorf("unsupported date type %T", src)
+	}
+	return nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullInt(n int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(n), Valid: n != 0}
+}
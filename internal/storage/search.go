@@ -0,0 +1,327 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"log"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fevse/songlib/internal/translit"
+)
+
+// tsQuery matches either the Russian or the English reading of a query,
+// songs are indexed with both. The transliterated query in $2 matches the
+// band and title keys, so names are found in either script.
+const tsQuery = `(websearch_to_tsquery('russian', $1) || websearch_to_tsquery('english', $1) ||
+	websearch_to_tsquery('simple', $2))`
+
+// Search finds songs by words of their title, band or lyrics, best
+// matches first.
+func (r *Storage) Search(ctx context.Context, q SearchQuery) (*SearchPage, error) {
+	if !r.dialect.textSearch {
+		return r.searchInGo(ctx, q)
+	}
+
+	page := &SearchPage{Items: []SearchResult{}, Limit: q.Limit, Offset: q.Offset}
+	query := `SELECT count(*) FROM songs WHERE search_vector @@ ` + tsQuery
+	folded := translit.Fold(q.Query)
+	if err := r.conn().QueryRow(ctx, query, q.Query, folded).Scan(&page.Total); err != nil {
+		log.Printf("Error counting songs: %v", err)
+		return nil, err
+	}
+
+	query = `
+		SELECT ` + songColumns + `, ts_rank(search_vector, terms.q) AS rank,
+			COALESCE(ts_headline('russian', text, terms.q, 'MaxWords=20, MinWords=10'), '')
+		FROM songs, (SELECT ` + tsQuery + ` AS q) AS terms
+		WHERE search_vector @@ terms.q
+		ORDER BY rank DESC, id
+		LIMIT $3 OFFSET $4`
+	rows, err := r.conn().Query(ctx, query, q.Query, folded, q.Limit, q.Offset)
+	if err != nil {
+		log.Printf("Error searching songs: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var result SearchResult
+		if err := scanSong(searchScanner{rows, &result}, &result.Song); err != nil {
+			log.Printf("Error scanning song: %v", err)
+			return nil, err
+		}
+		page.Items = append(page.Items, result)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error searching songs: %v", err)
+		return nil, err
+	}
+
+	if err := loadTags(ctx, r.conn(), resultSongs(page.Items)...); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// searchScanner appends the rank and snippet of a result to the song
+// columns scanned by scanSong.
+type searchScanner struct {
+	row    scanner
+	result *SearchResult
+}
+
+func (s searchScanner) Scan(dest ...any) error {
+	return s.row.Scan(append(dest, &s.result.Rank, &s.result.Snippet)...)
+}
+
+// searchInGo is the search of databases without full-text search, it
+// reads all songs and matches them with searchSongs.
+func (r *Storage) searchInGo(ctx context.Context, q SearchQuery) (*SearchPage, error) {
+	songs, err := r.allSongs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	page := searchSongs(songs, q)
+	if err := loadTags(ctx, r.conn(), resultSongs(page.Items)...); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// allSongs reads every song without tags, for searches done in Go.
+func (r *Storage) allSongs(ctx context.Context) ([]Song, error) {
+	rows, err := r.conn().Query(ctx, `SELECT `+songColumns+` FROM songs`)
+	if err != nil {
+		log.Printf("Error getting songs: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var songs []Song
+	for rows.Next() {
+		var song Song
+		if err := scanSong(rows, &song); err != nil {
+			log.Printf("Error scanning song: %v", err)
+			return nil, err
+		}
+		songs = append(songs, song)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error getting songs: %v", err)
+		return nil, err
+	}
+	return songs, nil
+}
+
+func resultSongs(results []SearchResult) []*Song {
+	songs := make([]*Song, len(results))
+	for i := range results {
+		songs[i] = &results[i].Song
+	}
+	return songs
+}
+
+// Field weights of the Go search, the default ts_rank weights of the
+// A, B and C labels of search_vector.
+const (
+	songWeight = 1.0
+	bandWeight = 0.4
+	textWeight = 0.2
+)
+
+// snippetWords is the length of a snippet, as MaxWords of ts_headline.
+const snippetWords = 20
+
+// searchTerm is a word or a quoted phrase of a search query.
+type searchTerm struct {
+	words   []string
+	exclude bool
+}
+
+// parseSearch splits a web search query into groups separated by "or".
+// As in websearch_to_tsquery, a song matches a group when it has every
+// term of the group and none of its excluded terms.
+func parseSearch(query string) [][]searchTerm {
+	groups := [][]searchTerm{nil}
+	for query != "" {
+		query = strings.TrimLeftFunc(query, unicode.IsSpace)
+		exclude := strings.HasPrefix(query, "-")
+		query = strings.TrimPrefix(query, "-")
+
+		var raw string
+		if rest, ok := strings.CutPrefix(query, `"`); ok {
+			raw, query, _ = strings.Cut(rest, `"`)
+		} else {
+			end := strings.IndexFunc(query, unicode.IsSpace)
+			if end < 0 {
+				end = len(query)
+			}
+			raw, query = query[:end], query[end:]
+		}
+
+		if strings.EqualFold(raw, "or") && !exclude {
+			if len(groups[len(groups)-1]) > 0 {
+				groups = append(groups, nil)
+			}
+			continue
+		}
+		words := searchWords(raw)
+		if len(words) == 0 {
+			continue
+		}
+		for i, w := range words {
+			words[i] = searchStem(w)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], searchTerm{words: words, exclude: exclude})
+	}
+	return slices.DeleteFunc(groups, func(g []searchTerm) bool { return len(g) == 0 })
+}
+
+// searchWords splits s into lowercase words of letters and digits,
+// Cyrillic is transliterated so that either script matches the other.
+func searchWords(s string) []string {
+	return strings.FieldsFunc(translit.Fold(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// searchStem is a rough stand-in for the stemming of Postgres: long words
+// lose up to two trailing letters and match any word starting with the
+// rest, so "dreams" finds "dreaming".
+func searchStem(word string) string {
+	n := utf8.RuneCountInString(word)
+	if n <= 4 {
+		return word
+	}
+	runes := []rune(word)
+	return string(runes[:max(4, n-2)])
+}
+
+// count returns how many times the term occurs in the words.
+func (t searchTerm) count(words []string) int {
+	var n int
+	for i := 0; i+len(t.words) <= len(words); i++ {
+		matched := true
+		for j, w := range t.words {
+			if !strings.HasPrefix(words[i+j], w) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			n++
+		}
+	}
+	return n
+}
+
+// rankSong scores the song for the groups, 0 when it matches none of them.
+func rankSong(song Song, groups [][]searchTerm) float64 {
+	fields := []struct {
+		words  []string
+		weight float64
+	}{
+		{searchWords(song.Song), songWeight},
+		{searchWords(song.Group), bandWeight},
+		{searchWords(song.Text), textWeight},
+	}
+
+	var rank float64
+	for _, group := range groups {
+		var groupRank float64
+		matched := true
+		for _, term := range group {
+			var termRank float64
+			for _, f := range fields {
+				termRank += float64(term.count(f.words)) * f.weight
+			}
+			if (termRank > 0) == term.exclude {
+				matched = false
+				break
+			}
+			groupRank += termRank
+		}
+		if matched && groupRank > 0 {
+			rank += groupRank
+		}
+	}
+	return rank
+}
+
+// snippet returns up to snippetWords words of the text around the first
+// match, with matching words wrapped in <b>. Without a match it is the
+// beginning of the text, as with ts_headline.
+func snippet(text string, groups [][]searchTerm) string {
+	words := strings.Fields(text)
+	highlight := make([]bool, len(words))
+	first := -1
+	for i, word := range words {
+		for _, w := range searchWords(word) {
+			if matchesTerm(w, groups) {
+				highlight[i] = true
+			}
+		}
+		if highlight[i] && first < 0 {
+			first = i
+		}
+	}
+
+	start := max(first-snippetWords/4, 0)
+	end := min(start+snippetWords, len(words))
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		if i > start {
+			b.WriteByte(' ')
+		}
+		if highlight[i] {
+			b.WriteString("<b>" + words[i] + "</b>")
+		} else {
+			b.WriteString(words[i])
+		}
+	}
+	return b.String()
+}
+
+func matchesTerm(word string, groups [][]searchTerm) bool {
+	for _, group := range groups {
+		for _, term := range group {
+			if term.exclude {
+				continue
+			}
+			for _, w := range term.words {
+				if strings.HasPrefix(word, w) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// searchSongs is the search of SQLite and MemoryStorage, it ranks the
+// songs in Go and orders them like the Postgres search.
+func searchSongs(songs []Song, q SearchQuery) *SearchPage {
+	groups := parseSearch(q.Query)
+
+	var results []SearchResult
+	for _, song := range songs {
+		if rank := rankSong(song, groups); rank > 0 {
+			results = append(results, SearchResult{Song: song, Rank: rank, Snippet: snippet(song.Text, groups)})
+		}
+	}
+	slices.SortFunc(results, func(a, b SearchResult) int {
+		if c := cmp.Compare(b.Rank, a.Rank); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	page := &SearchPage{Items: []SearchResult{}, Total: len(results), Limit: q.Limit, Offset: q.Offset}
+	results = results[min(q.Offset, len(results)):]
+	page.Items = append(page.Items, results[:min(q.Limit, len(results))]...)
+	return page
+}
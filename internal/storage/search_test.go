@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParseSearch(t *testing.T) {
+	term := func(exclude bool, words ...string) searchTerm {
+		return searchTerm{words: words, exclude: exclude}
+	}
+	tests := []struct {
+		query string
+		want  [][]searchTerm
+	}{
+		{"", [][]searchTerm{}},
+		{"Hole", [][]searchTerm{{term(false, "hole")}}},
+		{"dreams -nightmare", [][]searchTerm{{term(false, "drea"), term(true, "nightma")}}},
+		{`"black holes" muse`, [][]searchTerm{{term(false, "blac", "hole"), term(false, "muse")}}},
+		{"kino OR muse or", [][]searchTerm{{term(false, "kino")}, {term(false, "muse")}}},
+		{"or -or !!!", [][]searchTerm{{term(true, "or")}}},
+	}
+	for _, tt := range tests {
+		if got := parseSearch(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSearch(%q) = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	forEachRepository(t, testSearch)
+}
+
+func testSearch(t *testing.T, newRepo func(...*Song) Repository) {
+	ctx := context.Background()
+	repo := newRepo(
+		&Song{Group: "Muse", Song: "Hole", Text: "I keep on dreaming of you\n\nand the hole in the sky"},
+		&Song{Group: "Kino", Song: "Kukushka", Text: "Песен еще ненаписанных сколько"},
+		&Song{Group: "Muse", Song: "Dreams", Text: "Nothing here"},
+		&Song{Group: "Black Holes", Song: "Starlight", Text: "Far away"},
+		&Song{Group: "Кино", Song: "Группа крови", Text: "Теплое место"},
+	)
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		// A title match outranks a lyrics match.
+		{"dream", []int{3, 1}},
+		{"hole", []int{1, 4}},
+		{"hole -sky", []int{4}},
+		{`"black holes"`, []int{4}},
+		{"kukushka or starlight", []int{2, 4}},
+		{"ненаписанные", []int{2}},
+		{"queen", []int{}},
+		// Names match in either script.
+		{"gruppa krovi", []int{5}},
+		{"кукушка", []int{2}},
+	}
+	for _, tt := range tests {
+		page, err := repo.Search(ctx, SearchQuery{Query: tt.query, Limit: 10})
+		if err != nil {
+			t.Errorf("Search(%q): %v", tt.query, err)
+			continue
+		}
+		got := []int{}
+		for _, result := range page.Items {
+			got = append(got, result.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) || page.Total != len(tt.want) {
+			t.Errorf("Search(%q) = %v of %d, want %v", tt.query, got, page.Total, tt.want)
+		}
+	}
+
+	page, err := repo.Search(ctx, SearchQuery{Query: "sky", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "and the hole in the <b>sky</b>"; len(page.Items) != 1 || page.Items[0].Snippet != want {
+		t.Errorf("snippet = %+v, want %q", page.Items, want)
+	}
+
+	page, err = repo.Search(ctx, SearchQuery{Query: "hole", Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 2 || len(page.Items) != 1 || page.Items[0].ID != 4 {
+		t.Errorf("second page = %+v, want song 4 of 2", page)
+	}
+}
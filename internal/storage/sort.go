@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"cmp"
+	"strings"
+)
+
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+type Sort []SortKey
+
+// ParseSort parses a comma separated list of fields, a leading "-" means
+// descending order, e.g. "-releaseDate,group".
+func ParseSort(value string) (Sort, error) {
+	var sort Sort
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		key := SortKey{Field: strings.TrimPrefix(name, "-"), Desc: strings.HasPrefix(name, "-")}
+		if f, ok := songFields[key.Field]; !ok || f.noSort {
+			return nil, &FilterError{Field: key.Field, Allowed: SortFields()}
+		}
+		sort = append(sort, key)
+	}
+	return sort, nil
+}
+
+// orderBy always ends with id so that rows with equal sort keys keep
+// the same order between pages.
+func (s Sort) orderBy() string {
+	var terms []string
+	for _, key := range s {
+		term := songFields[key.Field].sortExpr()
+		if key.Desc {
+			term += " DESC"
+		}
+		terms = append(terms, term)
+	}
+	terms = append(terms, "id")
+	return " ORDER BY " + strings.Join(terms, ", ")
+}
+
+func (s Sort) String() string {
+	names := make([]string, len(s))
+	for i, key := range s {
+		names[i] = key.Field
+		if key.Desc {
+			names[i] = "-" + key.Field
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// compare orders songs the same way orderBy does.
+func (s Sort) compare(a, b Song) int {
+	for _, key := range s {
+		c := strings.Compare(a.sortValue(key.Field), b.sortValue(key.Field))
+		if key.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
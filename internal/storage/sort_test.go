@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		value, orderBy string
+		want           Sort
+	}{
+		{"", " ORDER BY id", nil},
+		{"group", " ORDER BY band, id", Sort{{Field: "group"}}},
+		{"-releaseDate, song", " ORDER BY COALESCE(release_date, '0001-01-01') DESC, song, id", Sort{{Field: "releaseDate", Desc: true}, {Field: "song"}}},
+	}
+	for _, tt := range tests {
+		got, err := ParseSort(tt.value)
+		if err != nil {
+			t.Errorf("ParseSort(%q): %v", tt.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) || got.orderBy() != tt.orderBy {
+			t.Errorf("ParseSort(%q) = %+v %q, want %+v %q", tt.value, got, got.orderBy(), tt.want, tt.orderBy)
+		}
+	}
+
+	var filterErr *FilterError
+	if _, err := ParseSort("group,-genre"); !errors.As(err, &filterErr) || filterErr.Field != "genre" {
+		t.Errorf("ParseSort of an unknown field err = %v, want *FilterError for genre", err)
+	}
+}
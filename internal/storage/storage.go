@@ -1,118 +1,350 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"log"
+	"path/filepath"
 	"strconv"
 
-	"github.com/pressly/goose"
+	"github.com/pressly/goose/v3"
+
+	"github.com/fevse/songlib/internal/translit"
+	"github.com/fevse/songlib/migrations"
 )
 
 type Storage struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect dialect
+}
+
+// NewStorage creates a storage on top of db opened with the given
+// driver, "postgres" or "sqlite".
+func NewStorage(db *sql.DB, driver string) *Storage {
+	d, ok := dialects[driver]
+	if !ok {
+		d = dialects["postgres"]
+	}
+	return &Storage{db: db, dialect: d}
 }
 
-func NewStorage(db *sql.DB) *Storage {
-	return &Storage{db: db}
+func (r *Storage) conn() conn {
+	return conn{q: r.db, dialect: &r.dialect}
 }
 
-func (s *Storage) Migrate() error {
-	if err := goose.SetDialect("postgres"); err != nil {
+// Migrate applies all pending migrations.
+func (s *Storage) Migrate(ctx context.Context) error {
+	return s.RunMigrations(ctx, "up")
+}
+
+// RunMigrations runs a goose command, e.g. up, down, status or redo,
+// against the migrations embedded into the binary.
+func (s *Storage) RunMigrations(ctx context.Context, command string, args ...string) error {
+	goose.SetBaseFS(migrations.FS)
+	if err := goose.SetDialect(s.dialect.goose); err != nil {
 		log.Printf("Error migration settings: %v", err)
 		return err
 	}
-	if err := goose.Up(s.db, "migrations"); err != nil {
+	if err := goose.RunContext(ctx, command, s.db, s.dialect.migrations, args...); err != nil {
 		log.Printf("Error migrations: %v", err)
 		return err
 	}
 	return nil
 }
 
-func (r *Storage) Create(song *Song) error {
+// CreateMigration writes a new SQL migration for the driver into the
+// migrations directory on disk, so it has to run from the repository root.
+func CreateMigration(driver, name string) error {
+	d, ok := dialects[driver]
+	if !ok {
+		return fmt.Errorf("unknown driver %q", driver)
+	}
+
+	goose.SetBaseFS(nil)
+	return goose.Create(nil, filepath.Join("migrations", d.migrations), name, "sql")
+}
+
+// SetUniqueSongs adds or drops songs_keys_key, the unique index on the band
+// and title keys. Songs with an empty key are left out of it, their names
+// have no letters or digits to compare.
+func (r *Storage) SetUniqueSongs(ctx context.Context, unique bool) error {
+	query := `DROP INDEX IF EXISTS songs_keys_key`
+	if unique {
+		query = `CREATE UNIQUE INDEX IF NOT EXISTS songs_keys_key ON songs (band_key, song_key)
+			WHERE band_key <> '' AND song_key <> ''`
+	}
+	_, err := r.conn().Exec(ctx, query)
+	if errors.Is(mapError(err), ErrConflict) {
+		return ErrDuplicateSongs
+	} else if err != nil {
+		log.Printf("Error setting unique songs: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Create inserts the song, linking it to its artist, see resolveArtist.
+func (r *Storage) Create(ctx context.Context, song *Song) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	c := conn{q: tx, dialect: &r.dialect}
+	if err := resolveArtist(ctx, c, song); err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO songs (band, song, release_date, text, link)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO songs (band, artist_id, song, release_date, release_date_precision, text, link, band_key, song_key)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id`
-	err := r.db.QueryRow(
-		query,
-		song.Group, song.Song, song.ReleaseDate,
-		song.Text, song.Link).Scan(&song.ID)
+	err = c.QueryRow(
+		ctx, query,
+		song.Group, song.ArtistID, song.Song, nullString(song.ReleaseDate), nullString(song.ReleaseDatePrecision),
+		song.Text, song.Link, translit.Key(song.Group), translit.Key(song.Song)).Scan(&song.ID)
 	if err != nil {
 		log.Printf("Error creating song: %v", err)
+		return mapError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
 		return err
 	}
 	return nil
 }
 
-func (r *Storage) GetByID(id int) (*Song, error) {
-	query := `SELECT * FROM songs WHERE id = $1`
-	row := r.db.QueryRow(query, id)
+// querier is implemented by both *sql.DB and *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (r *Storage) GetByID(ctx context.Context, id int) (*Song, error) {
+	return getByID(ctx, r.conn(), id, "")
+}
+
+func getByID(ctx context.Context, c conn, id int, lock string) (*Song, error) {
+	query := `SELECT ` + songColumns + ` FROM songs WHERE id = $1` + lock
+	row := c.QueryRow(ctx, query, id)
 
 	var song Song
-	err := row.Scan(&song.ID, &song.Group, &song.Song, &song.ReleaseDate, &song.Text, &song.Link)
-	if err != nil {
+	err := scanSong(row, &song)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
 		log.Printf("Error getting song: %v", err)
 		return nil, err
 	}
+	if err := loadTags(ctx, c, &song); err != nil {
+		return nil, err
+	}
 	return &song, nil
 }
 
-func (r *Storage) Update(song *Song) error {
-	query := `
-		UPDATE songs
-		SET band = $1, song = $2, release_date = $3, text = $4, link = $5
-		WHERE id = $6`
-	_, err := r.db.Exec(query, song.Group, song.Song, song.ReleaseDate, song.Text, song.Link, song.ID)
+// GetByName returns the oldest song with the band and title keys of group
+// and title, see translit.Key. A band or title with an empty key, one of
+// punctuation only, names no song.
+func (r *Storage) GetByName(ctx context.Context, group, title string) (*Song, error) {
+	band, key := translit.Key(group), translit.Key(title)
+	if band == "" || key == "" {
+		return nil, ErrNotFound
+	}
+
+	c := r.conn()
+	query := `SELECT ` + songColumns + ` FROM songs WHERE band_key = $1 AND song_key = $2 ORDER BY id LIMIT 1`
+	row := c.QueryRow(ctx, query, band, key)
+
+	var song Song
+	err := scanSong(row, &song)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		log.Printf("Error getting song: %v", err)
+		return nil, err
+	}
+	if err := loadTags(ctx, c, &song); err != nil {
+		return nil, err
+	}
+	return &song, nil
+}
+
+func (r *Storage) Update(ctx context.Context, song *Song) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Printf("Error updating song: %v", err)
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := update(ctx, conn{q: tx, dialect: &r.dialect}, song); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
 		return err
 	}
 	return nil
 }
 
-func (r *Storage) Delete(id int) error {
+func update(ctx context.Context, c conn, song *Song) error {
+	if err := resolveArtist(ctx, c, song); err != nil {
+		return err
+	}
+
+	// Album fields and tags are not updated here, they are read back to
+	// keep the song complete.
+	query := `
+		UPDATE songs
+		SET band = $1, artist_id = $2, song = $3, release_date = $4, release_date_precision = $5, text = $6, link = $7,
+			band_key = $8, song_key = $9
+		WHERE id = $10
+		RETURNING COALESCE(album_id, 0), COALESCE(disc_number, 0), COALESCE(track_number, 0)`
+	err := c.QueryRow(ctx, query,
+		song.Group, song.ArtistID, song.Song, nullString(song.ReleaseDate), nullString(song.ReleaseDatePrecision),
+		song.Text, song.Link, translit.Key(song.Group), translit.Key(song.Song), song.ID).Scan(&song.AlbumID, &song.DiscNumber, &song.TrackNumber)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	} else if err != nil {
+		log.Printf("Error updating song: %v", err)
+		return mapError(err)
+	}
+	return loadTags(ctx, c, song)
+}
+
+// UpdateFunc reads the song with its row locked, lets fn modify it and
+// writes the result back, all in one transaction. If fn fails nothing
+// is written.
+func (r *Storage) UpdateFunc(ctx context.Context, id int, fn func(song *Song) error) (*Song, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	c := conn{q: tx, dialect: &r.dialect}
+	song, err := getByID(ctx, c, id, r.dialect.lock)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fn(song); err != nil {
+		return nil, err
+	}
+	song.ID = id
+
+	if err := update(ctx, c, song); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return nil, err
+	}
+	return song, nil
+}
+
+func (r *Storage) Delete(ctx context.Context, id int) error {
 	query := `DELETE FROM songs WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	res, err := r.conn().Exec(ctx, query, id)
 	if err != nil {
 		log.Printf("Error deleting song: %v", err)
 		return err
 	}
+	return checkAffected(res)
+}
+
+// checkAffected reports ErrNotFound when a statement matched no rows.
+func checkAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
 	return nil
 }
 
-func (r *Storage) GetList(filter map[string]string, limit, offset int) ([]Song, error) {
-	query := `SELECT * FROM songs WHERE 1=1`
-	args := []any{}
-	counter := 1
+// GetList returns a page of songs. With a cursor the page starts right
+// after the row the cursor points to and Offset is ignored. Total counts
+// all rows matching the filter.
+func (r *Storage) GetList(ctx context.Context, q ListQuery) (*SongPage, error) {
+	where, args := q.where(&r.dialect, nil)
+
+	page := &SongPage{Items: []Song{}, Limit: q.Limit, Offset: q.Offset, Cursor: q.Cursor}
+	if err := r.conn().QueryRow(ctx, `SELECT count(*) FROM songs`+where, args...).Scan(&page.Total); err != nil {
+		log.Printf("Error counting songs: %v", err)
+		return nil, err
+	}
 
-	for key, value := range filter {
-		if key == "group" {
-			key = "band"
+	if q.Cursor != "" {
+		c, err := decodeCursor(q.Cursor, q.Sort)
+		if err != nil {
+			return nil, err
 		}
-		query += " AND " + key + " = $" + strconv.Itoa(counter)
-		args = append(args, value)
-		counter++
+
+		var after string
+		after, args = c.after(q.Sort, args)
+		where += after
 	}
 
-	query += " LIMIT $" + strconv.Itoa(counter) + " OFFSET $" + strconv.Itoa(counter+1)
-	args = append(args, limit, offset)
+	query := `SELECT ` + songColumns + ` FROM songs` + where + q.Sort.orderBy()
+
+	// One extra row tells whether there is a next page.
+	args = append(args, q.Limit+1)
+	query += " LIMIT $" + strconv.Itoa(len(args))
+	if q.Cursor == "" {
+		args = append(args, q.Offset)
+		query += " OFFSET $" + strconv.Itoa(len(args))
+	}
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.conn().Query(ctx, query, args...)
 	if err != nil {
 		log.Printf("Error getting songs: %v", err)
 		return nil, err
 	}
 	defer rows.Close()
 
-	var songs []Song
+	songs := []Song{}
 	for rows.Next() {
 		var song Song
-		err := rows.Scan(&song.ID, &song.Group, &song.Song, &song.ReleaseDate, &song.Text, &song.Link)
+		err := scanSong(rows, &song)
 		if err != nil {
 			log.Printf("Error scanning song: %v", err)
 			return nil, err
 		}
 		songs = append(songs, song)
 	}
-	return songs, nil
+	if err := rows.Err(); err != nil {
+		log.Printf("Error getting songs: %v", err)
+		return nil, err
+	}
+
+	page.Items = songs
+	if len(songs) > q.Limit {
+		page.Items = songs[:q.Limit]
+		page.NextCursor = encodeCursor(q.Sort, page.Items[q.Limit-1])
+	}
+	if err := loadTags(ctx, r.conn(), songPointers(page.Items)...); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func songPointers(songs []Song) []*Song {
+	ptrs := make([]*Song, len(songs))
+	for i := range songs {
+		ptrs[i] = &songs[i]
+	}
+	return ptrs
 }
@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newTestSQLite returns a storage on an empty SQLite database.
+func newTestSQLite(t *testing.T) *Storage {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "songs.db")+"?_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	s := NewStorage(db, "sqlite")
+	if err := s.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestStorageCanceled(t *testing.T) {
+	s := newTestSQLite(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := s.Create(ctx, &Song{Group: "Muse", Song: "Hole"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Create err = %v, want context.Canceled", err)
+	}
+	if _, err := s.GetList(ctx, ListQuery{Limit: 10}); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetList err = %v, want context.Canceled", err)
+	}
+}
+
+func TestMigrationsDown(t *testing.T) {
+	s := newTestSQLite(t)
+	ctx := context.Background()
+
+	if err := s.RunMigrations(ctx, "down-to", "0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Create(ctx, &Song{Group: "Muse", Song: "Hole"}); err != nil {
+		t.Errorf("Create after migrating down and up: %v", err)
+	}
+}
+
+func TestReleaseDateMigration(t *testing.T) {
+	s := newTestSQLite(t)
+	ctx := context.Background()
+	if err := s.RunMigrations(ctx, "down-to", "20250320190526"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, date := range []any{"16.07.2006", "6.7.2006", "07.2006", "2006", " ", nil} {
+		title := fmt.Sprintf("Song %d", i+1)
+		if _, err := s.db.Exec(`INSERT INTO songs (band, song, release_date, text, link) VALUES ('Muse', ?, ?, '', '')`, title, date); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := s.GetList(ctx, ListQuery{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, song := range page.Items {
+		got = append(got, song.ReleaseDate+" "+song.ReleaseDatePrecision)
+	}
+	if want := []string{"2006-07-16 day", "2006-07-06 day", "2006-07-01 month", "2006-01-01 year", " ", " "}; !reflect.DeepEqual(got, want) {
+		t.Errorf("migrated dates = %q, want %q", got, want)
+	}
+}
+
+func TestReleaseDateMigrationErrors(t *testing.T) {
+	s := newTestSQLite(t)
+	ctx := context.Background()
+	if err := s.RunMigrations(ctx, "down-to", "20250320190526"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, date := range []string{"16.07.2006", "31.02.2006", "someday"} {
+		title := fmt.Sprintf("Song %d", i+1)
+		if _, err := s.db.Exec(`INSERT INTO songs (band, song, release_date, text, link) VALUES ('Muse', ?, ?, '', '')`, title, date); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := s.Migrate(ctx)
+	if err == nil || !strings.Contains(err.Error(), "songs 2 (2006-02-31), 3 (someday)") {
+		t.Fatalf("Migrate err = %v, want the dates it cannot convert", err)
+	}
+
+	// The dates are kept, once fixed the migration passes.
+	if _, err := s.db.Exec(`UPDATE songs SET release_date = '28.02.2006' WHERE id = 2`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.db.Exec(`UPDATE songs SET release_date = NULL WHERE id = 3`); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	song, err := s.GetByID(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if song.ReleaseDate != "2006-02-28" || song.ReleaseDatePrecision != "day" {
+		t.Errorf("migrated date = %q %q, want 2006-02-28 day", song.ReleaseDate, song.ReleaseDatePrecision)
+	}
+}
+
+func TestArtistsMigration(t *testing.T) {
+	s := newTestSQLite(t)
+	ctx := context.Background()
+	if err := s.RunMigrations(ctx, "down-to", "20250401120000"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, band := range []string{"Muse", "muse  ", "Björk", "BJÖRK", "Kino", " "} {
+		title := fmt.Sprintf("Song %d", i+1)
+		if _, err := s.db.Exec(`INSERT INTO songs (band, song, text, link) VALUES (?, ?, '', '')`, band, title); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := s.GetList(ctx, ListQuery{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, song := range page.Items {
+		got = append(got, fmt.Sprintf("%s %d", song.Group, song.ArtistID))
+	}
+	// Spellings of a band are one artist, named by the first of them in
+	// byte order, a blank band has none.
+	if want := []string{"Muse 3", "Muse 3", "BJÖRK 1", "BJÖRK 1", "Kino 2", "  0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("songs after the migration = %q, want %q", got, want)
+	}
+}
+
+func TestTranslitKeysMigration(t *testing.T) {
+	s := newTestSQLite(t)
+	ctx := context.Background()
+	if err := s.RunMigrations(ctx, "down-to", "20250505120000"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.db.Exec(`INSERT INTO songs (band, song, text, link) VALUES ('КИНО', 'Группа Крови!', '', '')`); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var band, song string
+	if err := s.db.QueryRow(`SELECT band_key, song_key FROM songs`).Scan(&band, &song); err != nil {
+		t.Fatal(err)
+	}
+	if band != "kino" || song != "gruppa krovi" {
+		t.Errorf("keys after the migration = %q, %q, want kino, gruppa krovi", band, song)
+	}
+}
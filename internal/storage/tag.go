@@ -0,0 +1,225 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type TagMode string
+
+const (
+	// TagModeAll matches songs having every tag of the query.
+	TagModeAll TagMode = "all"
+	// TagModeAny matches songs having at least one tag of the query.
+	TagModeAny TagMode = "any"
+)
+
+// TagName normalizes a tag name, "Rock " and "rock" are the same tag.
+func TagName(name string) string {
+	return nameKey(name)
+}
+
+// where builds the WHERE clause of the filter and the tags of the query.
+func (q ListQuery) where(d *dialect, args []any) (string, []any) {
+	where, args := q.Filter.where(d, args)
+	if len(q.Tags) == 0 {
+		return where, args
+	}
+
+	placeholders := make([]string, len(q.Tags))
+	for i, tag := range q.Tags {
+		args = append(args, tag)
+		placeholders[i] = "$" + strconv.Itoa(len(args))
+	}
+
+	where += ` AND id IN (
+		SELECT song_tags.song_id FROM song_tags JOIN tags ON tags.id = song_tags.tag_id
+		WHERE tags.name IN (` + strings.Join(placeholders, ", ") + `)`
+	if q.TagMode != TagModeAny {
+		args = append(args, len(q.Tags))
+		where += ` GROUP BY song_tags.song_id HAVING count(*) = $` + strconv.Itoa(len(args))
+	}
+	return where + `)`, args
+}
+
+// match is the in-memory counterpart of where.
+func (q ListQuery) match(song Song) bool {
+	if !q.Filter.match(song) {
+		return false
+	}
+	if len(q.Tags) == 0 {
+		return true
+	}
+
+	var found int
+	for _, tag := range q.Tags {
+		if slices.Contains(song.Tags, tag) {
+			found++
+		}
+	}
+	if q.TagMode == TagModeAny {
+		return found > 0
+	}
+	return found == len(q.Tags)
+}
+
+// loadTags fills in the tags of the songs, a song may be passed more
+// than once.
+func loadTags(ctx context.Context, c conn, songs ...*Song) error {
+	if len(songs) == 0 {
+		return nil
+	}
+
+	byID := make(map[int][]*Song, len(songs))
+	ids := make([]int, len(songs))
+	for i, song := range songs {
+		song.Tags = nil
+		byID[song.ID] = append(byID[song.ID], song)
+		ids[i] = song.ID
+	}
+
+	match, arg := c.dialect.anyID("song_tags.song_id", "$1", ids)
+	query := `
+		SELECT song_tags.song_id, tags.name
+		FROM song_tags JOIN tags ON tags.id = song_tags.tag_id
+		WHERE ` + match + `
+		ORDER BY tags.name`
+	rows, err := c.Query(ctx, query, arg)
+	if err != nil {
+		log.Printf("Error getting song tags: %v", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			log.Printf("Error scanning song tag: %v", err)
+			return err
+		}
+		for _, song := range byID[id] {
+			song.Tags = append(song.Tags, name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error getting song tags: %v", err)
+		return err
+	}
+	return nil
+}
+
+// AddTags tags the song, tags that do not exist yet are created. The
+// kind of an existing tag is only set when it had none.
+func (r *Storage) AddTags(ctx context.Context, songID int, tags []Tag) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	c := conn{q: tx, dialect: &r.dialect}
+	var id int
+	err = c.QueryRow(ctx, `SELECT id FROM songs WHERE id = $1`+r.dialect.lock, songID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	} else if err != nil {
+		log.Printf("Error getting song: %v", err)
+		return err
+	}
+
+	for _, tag := range tags {
+		query := `
+			INSERT INTO tags (name, kind) VALUES ($1, $2)
+			ON CONFLICT (name) DO UPDATE SET kind = excluded.kind
+			WHERE tags.kind = '' AND excluded.kind <> ''`
+		if _, err := c.Exec(ctx, query, TagName(tag.Name), tag.Kind); err != nil {
+			log.Printf("Error creating tag: %v", err)
+			return err
+		}
+
+		query = `
+			INSERT INTO song_tags (song_id, tag_id)
+			SELECT CAST($1 AS INTEGER), id FROM tags WHERE name = $2
+			ON CONFLICT DO NOTHING`
+		if _, err := c.Exec(ctx, query, songID, TagName(tag.Name)); err != nil {
+			log.Printf("Error tagging song: %v", err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return err
+	}
+	return nil
+}
+
+// RemoveTags removes the tags from the song, tags the song does not have
+// are ignored.
+func (r *Storage) RemoveTags(ctx context.Context, songID int, names []string) error {
+	if _, err := r.GetByID(ctx, songID); err != nil {
+		return err
+	}
+
+	query := `
+		DELETE FROM song_tags
+		WHERE song_id = $1 AND tag_id = (SELECT id FROM tags WHERE name = $2)`
+	for _, name := range names {
+		if _, err := r.conn().Exec(ctx, query, songID, TagName(name)); err != nil {
+			log.Printf("Error removing song tag: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// Facets counts the tags of the songs matching the query, most used tags
+// first. Kind limits the counts to tags of that kind. Sort and pagination
+// of the query are ignored.
+func (r *Storage) Facets(ctx context.Context, q ListQuery, kind string) (*Facets, error) {
+	where, args := q.where(&r.dialect, nil)
+
+	facets := &Facets{Tags: []TagCount{}}
+	if err := r.conn().QueryRow(ctx, `SELECT count(*) FROM songs`+where, args...).Scan(&facets.Total); err != nil {
+		log.Printf("Error counting songs: %v", err)
+		return nil, err
+	}
+
+	query := `
+		SELECT tags.name, tags.kind, count(*)
+		FROM song_tags JOIN tags ON tags.id = song_tags.tag_id
+		WHERE song_tags.song_id IN (SELECT id FROM songs` + where + `)`
+	if kind != "" {
+		args = append(args, kind)
+		query += ` AND tags.kind = $` + strconv.Itoa(len(args))
+	}
+	query += ` GROUP BY tags.name, tags.kind ORDER BY count(*) DESC, tags.name`
+
+	rows, err := r.conn().Query(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error counting tags: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tc TagCount
+		if err := rows.Scan(&tc.Name, &tc.Kind, &tc.Count); err != nil {
+			log.Printf("Error scanning tag count: %v", err)
+			return nil, err
+		}
+		facets.Tags = append(facets.Tags, tc)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error counting tags: %v", err)
+		return nil, err
+	}
+	return facets, nil
+}
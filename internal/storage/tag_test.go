@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestTags(t *testing.T) {
+	forEachRepository(t, testTags)
+}
+
+func testTags(t *testing.T, newRepo func(...*Song) Repository) {
+	ctx := context.Background()
+	repo := newRepo(
+		&Song{Group: "Muse", Song: "Hole"},
+		&Song{Group: "Muse", Song: "Uprising"},
+		&Song{Group: "Kino", Song: "Kukushka"},
+	)
+
+	add := func(songID int, tags ...Tag) {
+		t.Helper()
+		if err := repo.AddTags(ctx, songID, tags); err != nil {
+			t.Fatalf("AddTags(%d): %v", songID, err)
+		}
+	}
+	add(1, Tag{Name: "Rock "}, Tag{Name: "live"})
+	// The kind of a tag is set once, by the first tagging that has one.
+	add(2, Tag{Name: "rock", Kind: "genre"}, Tag{Name: "2000s", Kind: "era"})
+	add(3, Tag{Name: "ROCK", Kind: "mood"}, Tag{Name: "post-punk", Kind: "genre"})
+	add(3, Tag{Name: "rock"})
+
+	if err := repo.AddTags(ctx, 9, []Tag{{Name: "rock"}}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("AddTags of a missing song err = %v, want ErrNotFound", err)
+	}
+	song, err := repo.GetByID(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"post-punk", "rock"}; !reflect.DeepEqual(song.Tags, want) {
+		t.Errorf("tags of song 3 = %q, want %q", song.Tags, want)
+	}
+
+	tests := []struct {
+		name string
+		q    ListQuery
+		want []int
+	}{
+		{"all", ListQuery{Tags: []string{"rock", "live"}, TagMode: TagModeAll}, []int{1}},
+		{"any", ListQuery{Tags: []string{"live", "post-punk"}, TagMode: TagModeAny}, []int{1, 3}},
+		{"with a filter", ListQuery{Filter: Filter{{Field: "group", Op: OpEq, Value: "Muse"}}, Tags: []string{"rock"}, TagMode: TagModeAll}, []int{1, 2}},
+		{"unknown tag", ListQuery{Tags: []string{"jazz"}, TagMode: TagModeAny}, []int{}},
+	}
+	for _, tt := range tests {
+		tt.q.Limit = 10
+		page, err := repo.GetList(ctx, tt.q)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := songIDs(page.Items); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ids = %v, want %v", tt.name, got, tt.want)
+		}
+		if tt.name == "all" && !reflect.DeepEqual(page.Items[0].Tags, []string{"live", "rock"}) {
+			t.Errorf("%s: tags of the listed song = %q, want [live rock]", tt.name, page.Items[0].Tags)
+		}
+	}
+
+	facets, err := repo.Facets(ctx, ListQuery{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Facets{Total: 3, Tags: []TagCount{
+		{Name: "rock", Kind: "genre", Count: 3},
+		{Name: "2000s", Kind: "era", Count: 1},
+		{Name: "live", Count: 1},
+		{Name: "post-punk", Kind: "genre", Count: 1},
+	}}
+	if !reflect.DeepEqual(facets, want) {
+		t.Errorf("Facets = %+v, want %+v", facets, want)
+	}
+	facets, err = repo.Facets(ctx, ListQuery{Filter: Filter{{Field: "group", Op: OpEq, Value: "Kino"}}}, "genre")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = &Facets{Total: 1, Tags: []TagCount{
+		{Name: "post-punk", Kind: "genre", Count: 1},
+		{Name: "rock", Kind: "genre", Count: 1},
+	}}
+	if !reflect.DeepEqual(facets, want) {
+		t.Errorf("Facets of Kino genres = %+v, want %+v", facets, want)
+	}
+
+	if err := repo.RemoveTags(ctx, 1, []string{"ROCK", "jazz"}); err != nil {
+		t.Fatal(err)
+	}
+	if song, _ := repo.GetByID(ctx, 1); !reflect.DeepEqual(song.Tags, []string{"live"}) {
+		t.Errorf("tags after RemoveTags = %q, want [live]", song.Tags)
+	}
+	if err := repo.RemoveTags(ctx, 1, []string{"live"}); err != nil {
+		t.Fatal(err)
+	}
+	if song, _ := repo.GetByID(ctx, 1); song.Tags != nil {
+		t.Errorf("tags after removing all = %q, want none", song.Tags)
+	}
+	if err := repo.RemoveTags(ctx, 9, []string{"rock"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("RemoveTags of a missing song err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestAnyID(t *testing.T) {
+	tests := []struct {
+		driver    string
+		wantMatch string
+		wantArg   any
+	}{
+		{"postgres", `song_id = ANY($1)`, pq.Array([]int{1, 2, 3})},
+		{"sqlite", `song_id IN (SELECT value FROM json_each($1))`, "[1,2,3]"},
+	}
+	for _, tt := range tests {
+		d := dialects[tt.driver]
+		match, arg := d.anyID("song_id", "$1", []int{1, 2, 3})
+		if match != tt.wantMatch || !reflect.DeepEqual(arg, tt.wantArg) {
+			t.Errorf("%s: anyID = %q %v, want %q %v", tt.driver, match, arg, tt.wantMatch, tt.wantArg)
+		}
+	}
+}
+
+// TestLoadTagsOfManySongs loads the tags of more songs than SQLite takes
+// parameters in a statement.
+func TestLoadTagsOfManySongs(t *testing.T) {
+	s := newTestSQLite(t)
+	ctx := context.Background()
+	song := &Song{Group: "Muse", Song: "Hole"}
+	if err := s.Create(ctx, song); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddTags(ctx, song.ID, []Tag{{Name: "rock"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	songs := make([]*Song, 40000)
+	for i := range songs {
+		songs[i] = &Song{ID: i + 1}
+	}
+	if err := loadTags(ctx, s.conn(), songs...); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(songs[0].Tags, []string{"rock"}) || songs[1].Tags != nil {
+		t.Errorf("tags = %v, %v, want [rock] and none", songs[0].Tags, songs[1].Tags)
+	}
+}
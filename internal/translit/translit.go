@@ -0,0 +1,61 @@
+// Package translit transliterates Cyrillic to Latin and builds search keys
+// under which a name matches in either script.
+package translit
+
+import (
+	"strings"
+	"unicode"
+)
+
+// icao is the ICAO Doc 9303 scheme, used in Russian passports since 2013
+// and close to GOST R 52535.1-2006. The Ukrainian and Belarusian letters
+// follow the same document.
+var icao = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "ie", 'ы': "y", 'ь': "", 'э': "e", 'ю': "iu", 'я': "ia",
+	'ґ': "g", 'є': "ie", 'і': "i", 'ї': "i", 'ў': "u",
+}
+
+// Latin transliterates the Cyrillic letters of s with the ICAO scheme,
+// other characters are kept. Capital letters stay capital: "Щука" is
+// "Shchuka".
+func Latin(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		latin, ok := icao[unicode.ToLower(r)]
+		switch {
+		case !ok:
+			b.WriteRune(r)
+		case unicode.IsUpper(r) && latin != "":
+			b.WriteString(strings.ToUpper(latin[:1]) + latin[1:])
+		default:
+			b.WriteString(latin)
+		}
+	}
+	return b.String()
+}
+
+// Fold lowercases and transliterates s, then folds spellings that other
+// schemes and people use for the same sounds: "kh" becomes "h" (Khochu,
+// Hochu) and "y" becomes "i" (Tsoy, Tsoi; Pesnya, Pesnia). Characters
+// other than letters are kept, so search syntax survives.
+func Fold(s string) string {
+	s = Latin(strings.ToLower(s))
+	s = strings.ReplaceAll(s, "kh", "h")
+	return strings.ReplaceAll(s, "y", "i")
+}
+
+// Key returns the search key of s: its folded words of letters and digits
+// separated by single spaces. "Кино — Группа крови" and "Kino Gruppa
+// krovi" have the same key. Letters of other scripts are kept as they
+// are, so "Beyoncé" and "Beyonc" or two Greek titles do not share a key.
+// A name of punctuation only has an empty key.
+func Key(s string) string {
+	words := strings.FieldsFunc(Fold(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,71 @@
+package translit
+
+import "testing"
+
+func TestLatin(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"Muse", "Muse"},
+		{"Кино", "Kino"},
+		{"Щука", "Shchuka"},
+		{"ЖУК", "ZhUK"},
+		{"Цой", "Tsoi"},
+		{"Подъезд", "Podieezd"},
+		{"Мальчик", "Malchik"},
+		{"Вьюга", "Viuga"},
+		{"Ёлка", "Elka"},
+		{"Би-2", "Bi-2"},
+	}
+	for _, tt := range tests {
+		if got := Latin(tt.in); got != tt.want {
+			t.Errorf("Latin(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Хочу", "hochu"},
+		{"Khochu", "hochu"},
+		{"Tsoy", "tsoi"},
+		{"Песня", "pesnia"},
+		{"Pesnya", "pesnia"},
+		{`"Группа крови" -кино`, `"gruppa krovi" -kino`},
+	}
+	for _, tt := range tests {
+		if got := Fold(tt.in); got != tt.want {
+			t.Errorf("Fold(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"  ", ""},
+		{"Кино — Группа крови", "kino gruppa krovi"},
+		{"Kino Gruppa krovi", "kino gruppa krovi"},
+		{"AC/DC", "ac dc"},
+		{"Supermassive  Black Hole!", "supermassive black hole"},
+		{"Би-2", "bi 2"},
+		{"Ария", "ariia"},
+		{"Ariya", "ariia"},
+		{"Їжак", "izhak"},
+		{"Beyoncé", "beioncé"},
+		{"Beyonc", "beionc"},
+		{"Αφροδίτη", "αφροδίτη"},
+		{"美空ひばり", "美空ひばり"},
+		{"!!!", ""},
+	}
+	for _, tt := range tests {
+		if got := Key(tt.in); got != tt.want {
+			t.Errorf("Key(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
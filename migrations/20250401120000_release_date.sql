@@ -0,0 +1,70 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE songs ADD COLUMN release_date_precision VARCHAR(5);
+
+UPDATE songs SET release_date = NULLIF(btrim(release_date), '');
+
+UPDATE songs SET release_date_precision = CASE
+    WHEN release_date ~ '^\d{1,2}\.\d{1,2}\.\d{4}$' OR release_date ~ '^\d{4}-\d{2}-\d{2}$' THEN 'day'
+    WHEN release_date ~ '^\d{2}\.\d{4}$' OR release_date ~ '^\d{4}-\d{2}$' THEN 'month'
+    WHEN release_date ~ '^\d{4}$' THEN 'year'
+END;
+
+-- to_date that returns NULL instead of failing on dates out of range,
+-- only for the conversion.
+CREATE FUNCTION songlib_release_date(value TEXT, unit TEXT) RETURNS DATE LANGUAGE plpgsql AS $$
+BEGIN
+    RETURN CASE unit
+        WHEN 'day' THEN CASE
+            WHEN value ~ '^\d{4}' THEN to_date(value, 'YYYY-MM-DD')
+            ELSE to_date(value, 'DD.MM.YYYY')
+        END
+        WHEN 'month' THEN CASE
+            WHEN value ~ '^\d{4}' THEN to_date(value, 'YYYY-MM')
+            ELSE to_date(value, 'MM.YYYY')
+        END
+        WHEN 'year' THEN to_date(value, 'YYYY')
+    END;
+EXCEPTION WHEN others THEN
+    RETURN NULL;
+END
+$$;
+
+-- Dates in other formats or out of range, e.g. 31.02.2006, stop the
+-- migration instead of being lost. They have to be fixed or cleared by
+-- hand before migrating again.
+DO $$
+DECLARE
+    invalid TEXT;
+BEGIN
+    SELECT string_agg(id || ' (' || release_date || ')', ', ' ORDER BY id) INTO invalid
+    FROM songs
+    WHERE release_date IS NOT NULL AND songlib_release_date(release_date, release_date_precision) IS NULL;
+
+    IF invalid IS NOT NULL THEN
+        RAISE EXCEPTION 'cannot convert release_date of songs %', invalid;
+    END IF;
+END
+$$;
+
+ALTER TABLE songs ALTER COLUMN release_date TYPE DATE USING songlib_release_date(release_date, release_date_precision);
+
+DROP FUNCTION songlib_release_date(TEXT, TEXT);
+
+CREATE INDEX IF NOT EXISTS songs_release_date_idx ON songs (release_date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS songs_release_date_idx;
+
+-- Dates go back to the formats of the enrichment API, up to their
+-- precision.
+ALTER TABLE songs ALTER COLUMN release_date TYPE TEXT USING CASE release_date_precision
+    WHEN 'year' THEN to_char(release_date, 'YYYY')
+    WHEN 'month' THEN to_char(release_date, 'MM.YYYY')
+    ELSE to_char(release_date, 'DD.MM.YYYY')
+END;
+
+ALTER TABLE songs DROP COLUMN release_date_precision;
+-- +goose StatementEnd
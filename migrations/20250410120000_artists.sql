@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Artists of the existing songs are created from their bands by the Go
+-- migration that follows, lower() depends on the collation.
+CREATE TABLE IF NOT EXISTS artists (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    -- name_key is the lowercased name with collapsed spaces, "Muse" and
+    -- "muse " are the same artist.
+    name_key VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+ALTER TABLE songs ADD COLUMN artist_id INTEGER REFERENCES artists (id);
+
+CREATE INDEX IF NOT EXISTS songs_artist_id_idx ON songs (artist_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS songs_artist_id_idx;
+
+ALTER TABLE songs DROP COLUMN artist_id;
+
+DROP TABLE IF EXISTS artists;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS albums (
+    id SERIAL PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    artist_id INTEGER NOT NULL REFERENCES artists (id),
+    release_date DATE,
+    release_date_precision VARCHAR(5)
+);
+
+CREATE INDEX IF NOT EXISTS albums_artist_id_idx ON albums (artist_id);
+
+ALTER TABLE songs
+    ADD COLUMN album_id INTEGER REFERENCES albums (id),
+    ADD COLUMN disc_number INTEGER,
+    ADD COLUMN track_number INTEGER;
+
+CREATE UNIQUE INDEX IF NOT EXISTS songs_album_track_idx ON songs (album_id, disc_number, track_number);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS songs_album_track_idx;
+
+ALTER TABLE songs
+    DROP COLUMN album_id,
+    DROP COLUMN disc_number,
+    DROP COLUMN track_number;
+
+DROP TABLE IF EXISTS albums;
+-- +goose StatementEnd
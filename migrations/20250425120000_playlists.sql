@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS playlists (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS playlist_entries (
+    id SERIAL PRIMARY KEY,
+    playlist_id INTEGER NOT NULL REFERENCES playlists (id) ON DELETE CASCADE,
+    song_id INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    -- position starts at 1, entries are renumbered on every edit.
+    position INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS playlist_entries_playlist_idx ON playlist_entries (playlist_id, position);
+CREATE INDEX IF NOT EXISTS playlist_entries_song_id_idx ON playlist_entries (song_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS playlist_entries;
+
+DROP TABLE IF EXISTS playlists;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The catalogue mixes Russian and English, every field is indexed with
+-- both configurations. Weights rank title matches above band and lyrics.
+ALTER TABLE songs ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', song), 'A') ||
+    setweight(to_tsvector('english', song), 'A') ||
+    setweight(to_tsvector('russian', band), 'B') ||
+    setweight(to_tsvector('english', band), 'B') ||
+    setweight(to_tsvector('russian', COALESCE(text, '')), 'C') ||
+    setweight(to_tsvector('english', COALESCE(text, '')), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS songs_search_idx ON songs USING GIN (search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS songs_search_idx;
+
+ALTER TABLE songs DROP COLUMN search_vector;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS songs_band_trgm_idx ON songs USING GIN (band gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS songs_song_trgm_idx ON songs USING GIN (song gin_trgm_ops);
+-- Matches queries naming both, e.g. "muse supermassive".
+CREATE INDEX IF NOT EXISTS songs_band_song_trgm_idx ON songs USING GIN ((band || ' ' || song) gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- The extension stays, other database objects may use it.
+DROP INDEX IF EXISTS songs_band_song_trgm_idx;
+DROP INDEX IF EXISTS songs_song_trgm_idx;
+DROP INDEX IF EXISTS songs_band_trgm_idx;
+-- +goose StatementEnd
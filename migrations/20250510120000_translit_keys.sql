@@ -0,0 +1,59 @@
+-- +goose Up
+-- +goose StatementBegin
+-- band_key and song_key are written by the application with translit.Key,
+-- so that "Kino Gruppa krovi" finds "Кино — Группа крови". The Go migration
+-- that follows fills them in for existing songs.
+ALTER TABLE songs
+    ADD COLUMN band_key TEXT NOT NULL DEFAULT '',
+    ADD COLUMN song_key TEXT NOT NULL DEFAULT '';
+
+-- Keys go into the search vector with the simple configuration, they
+-- are matched by the transliterated query.
+DROP INDEX IF EXISTS songs_search_idx;
+ALTER TABLE songs DROP COLUMN search_vector;
+ALTER TABLE songs ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', song), 'A') ||
+    setweight(to_tsvector('english', song), 'A') ||
+    setweight(to_tsvector('simple', song_key), 'A') ||
+    setweight(to_tsvector('russian', band), 'B') ||
+    setweight(to_tsvector('english', band), 'B') ||
+    setweight(to_tsvector('simple', band_key), 'B') ||
+    setweight(to_tsvector('russian', COALESCE(text, '')), 'C') ||
+    setweight(to_tsvector('english', COALESCE(text, '')), 'C')
+) STORED;
+CREATE INDEX IF NOT EXISTS songs_search_idx ON songs USING GIN (search_vector);
+
+-- Fuzzy matching compares keys, a typo in either script is found.
+DROP INDEX IF EXISTS songs_band_song_trgm_idx;
+DROP INDEX IF EXISTS songs_song_trgm_idx;
+DROP INDEX IF EXISTS songs_band_trgm_idx;
+CREATE INDEX IF NOT EXISTS songs_band_key_trgm_idx ON songs USING GIN (band_key gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS songs_song_key_trgm_idx ON songs USING GIN (song_key gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS songs_band_song_key_trgm_idx ON songs USING GIN ((band_key || ' ' || song_key) gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS songs_band_song_key_trgm_idx;
+DROP INDEX IF EXISTS songs_song_key_trgm_idx;
+DROP INDEX IF EXISTS songs_band_key_trgm_idx;
+CREATE INDEX IF NOT EXISTS songs_band_trgm_idx ON songs USING GIN (band gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS songs_song_trgm_idx ON songs USING GIN (song gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS songs_band_song_trgm_idx ON songs USING GIN ((band || ' ' || song) gin_trgm_ops);
+
+DROP INDEX IF EXISTS songs_search_idx;
+ALTER TABLE songs DROP COLUMN search_vector;
+ALTER TABLE songs ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', song), 'A') ||
+    setweight(to_tsvector('english', song), 'A') ||
+    setweight(to_tsvector('russian', band), 'B') ||
+    setweight(to_tsvector('english', band), 'B') ||
+    setweight(to_tsvector('russian', COALESCE(text, '')), 'C') ||
+    setweight(to_tsvector('english', COALESCE(text, '')), 'C')
+) STORED;
+CREATE INDEX IF NOT EXISTS songs_search_idx ON songs USING GIN (search_vector);
+
+ALTER TABLE songs
+    DROP COLUMN band_key,
+    DROP COLUMN song_key;
+-- +goose StatementEnd
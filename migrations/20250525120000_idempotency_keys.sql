@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Responses to requests sent with an Idempotency-Key header, replayed when
+-- a client retries the request.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    idempotency_key VARCHAR(255) PRIMARY KEY,
+    -- request_hash tells a retry from another request under the same key.
+    request_hash CHAR(64) NOT NULL,
+    -- status is 0 while the first request is in progress.
+    status INTEGER NOT NULL DEFAULT 0,
+    content_type TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS idempotency_keys;
+-- +goose StatementEnd
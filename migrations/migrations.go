@@ -0,0 +1,8 @@
+// Package migrations embeds the SQL migrations into the binary: Postgres
+// migrations live in the root, SQLite ones in the sqlite directory.
+package migrations
+
+import "embed"
+
+//go:embed *.sql sqlite/*.sql
+var FS embed.FS
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS songs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    band VARCHAR(255) NOT NULL,
+    song VARCHAR(255) NOT NULL,
+    release_date TEXT,
+    text TEXT,
+    link TEXT
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS songs;
+-- +goose StatementEnd
@@ -0,0 +1,66 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE songs ADD COLUMN release_date_precision VARCHAR(5);
+
+-- SQLite has no DATE type, dates are kept as ISO text that compares and
+-- sorts correctly.
+UPDATE songs SET release_date = NULLIF(trim(release_date), '');
+
+UPDATE songs SET
+    release_date = printf('%s-%02d-%02d', substr(release_date, -4),
+        CAST(substr(release_date, instr(release_date, '.') + 1) AS INTEGER), CAST(release_date AS INTEGER)),
+    release_date_precision = 'day'
+WHERE release_date GLOB '[0-9].[0-9].[0-9][0-9][0-9][0-9]'
+    OR release_date GLOB '[0-9].[0-9][0-9].[0-9][0-9][0-9][0-9]'
+    OR release_date GLOB '[0-9][0-9].[0-9].[0-9][0-9][0-9][0-9]'
+    OR release_date GLOB '[0-9][0-9].[0-9][0-9].[0-9][0-9][0-9][0-9]';
+
+UPDATE songs SET release_date_precision = 'day'
+WHERE release_date GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9]';
+
+UPDATE songs SET
+    release_date = substr(release_date, 4, 4) || '-' || substr(release_date, 1, 2) || '-01',
+    release_date_precision = 'month'
+WHERE release_date GLOB '[0-9][0-9].[0-9][0-9][0-9][0-9]';
+
+UPDATE songs SET release_date = release_date || '-01', release_date_precision = 'month'
+WHERE release_date GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]';
+
+UPDATE songs SET release_date = release_date || '-01-01', release_date_precision = 'year'
+WHERE release_date GLOB '[0-9][0-9][0-9][0-9]';
+
+-- Dates in other formats or out of range, e.g. 31.02.2006, stop the
+-- migration instead of being lost. They have to be fixed or cleared by
+-- hand before migrating again.
+CREATE TEMPORARY TABLE release_date_errors (songs TEXT);
+
+CREATE TEMPORARY TRIGGER release_date_errors_abort BEFORE INSERT ON release_date_errors
+BEGIN
+    SELECT RAISE(ABORT, 'cannot convert release_date of songs ' || NEW.songs);
+END;
+
+INSERT INTO release_date_errors
+SELECT group_concat(id || ' (' || release_date || ')', ', ') FROM songs
+WHERE release_date IS NOT NULL AND (release_date_precision IS NULL OR date(release_date) IS NOT release_date)
+HAVING count(*) > 0;
+
+DROP TABLE release_date_errors;
+
+CREATE INDEX IF NOT EXISTS songs_release_date_idx ON songs (release_date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS songs_release_date_idx;
+
+-- Dates go back to the formats of the enrichment API, up to their
+-- precision.
+UPDATE songs SET release_date = CASE release_date_precision
+    WHEN 'year' THEN substr(release_date, 1, 4)
+    WHEN 'month' THEN substr(release_date, 6, 2) || '.' || substr(release_date, 1, 4)
+    ELSE substr(release_date, 9, 2) || '.' || substr(release_date, 6, 2) || '.' || substr(release_date, 1, 4)
+END
+WHERE release_date IS NOT NULL;
+
+ALTER TABLE songs DROP COLUMN release_date_precision;
+-- +goose StatementEnd
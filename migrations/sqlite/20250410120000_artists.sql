@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Artists of the existing songs are created from their bands by the Go
+-- migration that follows, lower() of SQLite folds ASCII letters only.
+CREATE TABLE IF NOT EXISTS artists (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL,
+    name_key VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+ALTER TABLE songs ADD COLUMN artist_id INTEGER REFERENCES artists (id);
+
+CREATE INDEX IF NOT EXISTS songs_artist_id_idx ON songs (artist_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- SQLite cannot drop a column used by a foreign key, the table is rebuilt.
+CREATE TABLE songs_without_artist (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    band VARCHAR(255) NOT NULL,
+    song VARCHAR(255) NOT NULL,
+    release_date TEXT,
+    text TEXT,
+    link TEXT,
+    release_date_precision VARCHAR(5)
+);
+
+INSERT INTO songs_without_artist (id, band, song, release_date, text, link, release_date_precision)
+SELECT id, band, song, release_date, text, link, release_date_precision FROM songs;
+
+DROP TABLE songs;
+
+ALTER TABLE songs_without_artist RENAME TO songs;
+
+CREATE INDEX IF NOT EXISTS songs_release_date_idx ON songs (release_date);
+
+DROP TABLE IF EXISTS artists;
+-- +goose StatementEnd
@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS albums (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title VARCHAR(255) NOT NULL,
+    artist_id INTEGER NOT NULL REFERENCES artists (id),
+    release_date TEXT,
+    release_date_precision VARCHAR(5)
+);
+
+CREATE INDEX IF NOT EXISTS albums_artist_id_idx ON albums (artist_id);
+
+ALTER TABLE songs ADD COLUMN album_id INTEGER REFERENCES albums (id);
+ALTER TABLE songs ADD COLUMN disc_number INTEGER;
+ALTER TABLE songs ADD COLUMN track_number INTEGER;
+
+CREATE UNIQUE INDEX IF NOT EXISTS songs_album_track_idx ON songs (album_id, disc_number, track_number);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- SQLite cannot drop a column used by a foreign key, the table is rebuilt.
+CREATE TABLE songs_without_album (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    band VARCHAR(255) NOT NULL,
+    song VARCHAR(255) NOT NULL,
+    release_date TEXT,
+    text TEXT,
+    link TEXT,
+    release_date_precision VARCHAR(5),
+    artist_id INTEGER REFERENCES artists (id)
+);
+
+INSERT INTO songs_without_album (id, band, song, release_date, text, link, release_date_precision, artist_id)
+SELECT id, band, song, release_date, text, link, release_date_precision, artist_id FROM songs;
+
+DROP TABLE songs;
+
+ALTER TABLE songs_without_album RENAME TO songs;
+
+CREATE INDEX IF NOT EXISTS songs_release_date_idx ON songs (release_date);
+CREATE INDEX IF NOT EXISTS songs_artist_id_idx ON songs (artist_id);
+
+DROP TABLE IF EXISTS albums;
+-- +goose StatementEnd
@@ -0,0 +1,7 @@
+-- +goose Up
+-- SQLite has no tsvector, songs are searched in Go (storage/search.go).
+-- The migration keeps the versions of both dialects in step.
+SELECT 1;
+
+-- +goose Down
+SELECT 1;
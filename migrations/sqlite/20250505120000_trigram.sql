@@ -0,0 +1,7 @@
+-- +goose Up
+-- SQLite has no pg_trgm, similarity is computed in Go (storage/fuzzy.go).
+-- The migration keeps the versions of both dialects in step.
+SELECT 1;
+
+-- +goose Down
+SELECT 1;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- band_key and song_key are written by the application with translit.Key,
+-- the Go migration that follows fills them in for existing songs.
+ALTER TABLE songs ADD COLUMN band_key TEXT NOT NULL DEFAULT '';
+ALTER TABLE songs ADD COLUMN song_key TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE songs DROP COLUMN band_key;
+ALTER TABLE songs DROP COLUMN song_key;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A merged duplicate leaves its id behind, requests for it are redirected
+-- to the song it was merged into.
+CREATE TABLE IF NOT EXISTS song_redirects (
+    id INTEGER PRIMARY KEY,
+    song_id INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS song_redirects_song_id_idx ON song_redirects (song_id);
+
+-- Duplicates share their keys.
+CREATE INDEX IF NOT EXISTS songs_keys_idx ON songs (band_key, song_key);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS songs_keys_idx;
+
+DROP TABLE IF EXISTS song_redirects;
+-- +goose StatementEnd